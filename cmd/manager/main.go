@@ -16,6 +16,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/tekliner/rabbitmq-operator/pkg/apis"
 	"github.com/tekliner/rabbitmq-operator/pkg/controller"
+	"github.com/tekliner/rabbitmq-operator/pkg/webhook"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -30,6 +31,12 @@ var (
 )
 var log = logf.Log.WithName("cmd")
 
+// Admission webhooks are served on webhookPort, set it to 0 to disable them
+var (
+	webhookPort    int32
+	webhookCertDir string
+)
+
 func printVersion() {
 	log.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	log.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
@@ -45,6 +52,9 @@ func main() {
 	// controller-runtime)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 
+	pflag.Int32Var(&webhookPort, "webhook-port", 0, "port of admission webhooks server, 0 disables webhooks")
+	pflag.StringVar(&webhookCertDir, "webhook-cert-dir", "/etc/webhook/certs", "directory with tls.crt and tls.key for webhooks server")
+
 	pflag.Parse()
 
 	// Use a zap logr.Logger implementation. If none of the zap
@@ -112,6 +122,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup all admission webhooks
+	if webhookPort > 0 {
+		if err := webhook.AddToManager(mgr, webhookPort, webhookCertDir); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
 	// Create Service object to expose the metrics port.
 	_, err = metrics.ExposeMetricsPort(ctx, metricsPort)
 	if err != nil {
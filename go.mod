@@ -8,6 +8,7 @@ require (
 	github.com/appscode/jsonpatch v0.0.0-20190108182946-7c0e3b262f30 // indirect
 	github.com/certifi/gocertifi v0.0.0-20180118203423-deb3ae2ef261 // indirect
 	github.com/coreos/prometheus-operator v0.26.0
+	github.com/docker/spdystream v0.0.0-20170912183627-bc6354cbbc29 // indirect
 	github.com/emicklei/go-restful v2.9.0+incompatible // indirect
 	github.com/getsentry/raven-go v0.2.0
 	github.com/go-logr/logr v0.1.0
@@ -0,0 +1,128 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Default values of RabbitmqSpec
+const (
+	DefaultRabbitmqVhost                = "rabbit"
+	DefaultRabbitmqPolicyVhost          = "/"
+	DefaultRabbitmqK8SHost              = "kubernetes.default.svc.cluster.local"
+	DefaultRabbitmqK8SServiceDiscovery  = "svc.cluster.local"
+	DefaultRabbitmqK8SAddrType          = "hostname"
+	DefaultRabbitmqPeerDiscoveryBackend = "rabbit_peer_discovery_k8s"
+	DefaultRabbitmqNodeCleanupInterval  = 10
+	DefaultRabbitmqPartitionHandling    = "autoheal"
+	DefaultRabbitmqWatermarkPagingRatio = "0.8"
+	DefaultRabbitmqPrometheusImage      = "kbudde/rabbitmq-exporter:latest"
+	DefaultRabbitmqPodCPURequest        = "100m"
+	DefaultRabbitmqPodMemoryRequest     = "512Mi"
+	DefaultRabbitmqPodCPULimit          = "300m"
+	DefaultRabbitmqPodMemoryLimit       = "512Mi"
+	DefaultRabbitmqConfigUpdatePolicy   = RabbitmqConfigUpdateRollingRestart
+	DefaultRabbitmqDeletionPolicy       = RabbitmqDeletionPolicyRetain
+	// quorum queues with many messages need long time to sync a replica before the node may go down
+	DefaultRabbitmqTerminationGracePeriodSeconds = 3600
+	// keys of kubernetes.io/tls secrets, cert-manager adds ca.crt
+	DefaultRabbitmqSSLCacertfile = "ca.crt"
+	DefaultRabbitmqSSLCertfile   = "tls.crt"
+	DefaultRabbitmqSSLKeyfile    = "tls.key"
+	DefaultRabbitmqSSLVerify     = RabbitmqSSLVerifyNone
+	DefaultRabbitmqIssuerKind    = "Issuer"
+	DefaultRabbitmqIssuerGroup   = "cert-manager.io"
+)
+
+func defaultString(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func defaultResource(resources corev1.ResourceList, name corev1.ResourceName, value string) corev1.ResourceList {
+	if resources == nil {
+		resources = corev1.ResourceList{}
+	}
+	if _, ok := resources[name]; !ok {
+		resources[name] = resource.MustParse(value)
+	}
+	return resources
+}
+
+// DefaultVhost returns default_vhost of rabbitmq.conf
+func (r *Rabbitmq) DefaultVhost() string {
+	return defaultString(r.Spec.RabbitmqVhost, DefaultRabbitmqVhost)
+}
+
+// PolicyVhost returns vhost of policies without vhost. default_vhost is not written by Default(),
+// policies of Rabbitmq without default_vhost stay in "/" where they always were
+func (r *Rabbitmq) PolicyVhost() string {
+	return defaultString(r.Spec.RabbitmqVhost, DefaultRabbitmqPolicyVhost)
+}
+
+// Default sets default values to empty fields of Rabbitmq spec
+func (r *Rabbitmq) Default() {
+	if r.Spec.RabbitmqK8SHost == "" {
+		r.Spec.RabbitmqK8SHost = DefaultRabbitmqK8SHost
+	}
+
+	if r.Spec.RabbitmqK8SServiceDiscovery == "" {
+		r.Spec.RabbitmqK8SServiceDiscovery = DefaultRabbitmqK8SServiceDiscovery
+	}
+
+	if r.Spec.RabbitmqK8SAddrType == "" {
+		r.Spec.RabbitmqK8SAddrType = DefaultRabbitmqK8SAddrType
+	}
+
+	if r.Spec.RabbitmqK8SPeerDiscoveryBackend == "" {
+		r.Spec.RabbitmqK8SPeerDiscoveryBackend = DefaultRabbitmqPeerDiscoveryBackend
+	}
+
+	if r.Spec.RabbitmqClusterFormationNodeCleanup == 0 {
+		r.Spec.RabbitmqClusterFormationNodeCleanup = DefaultRabbitmqNodeCleanupInterval
+	}
+
+	if r.Spec.RabbitmqClusterPartitionHandling == "" {
+		r.Spec.RabbitmqClusterPartitionHandling = DefaultRabbitmqPartitionHandling
+	}
+
+	if r.Spec.RabbitmqMemoryHighWatermarkPagingRatio == "" {
+		r.Spec.RabbitmqMemoryHighWatermarkPagingRatio = DefaultRabbitmqWatermarkPagingRatio
+	}
+
+	if r.Spec.RabbitmqPrometheusExporterPort > 0 && r.Spec.RabbitmqPrometheusImage == "" {
+		r.Spec.RabbitmqPrometheusImage = DefaultRabbitmqPrometheusImage
+	}
+
+	if r.Spec.RabbitmqConfigUpdatePolicy == "" {
+		r.Spec.RabbitmqConfigUpdatePolicy = DefaultRabbitmqConfigUpdatePolicy
+	}
+
+	if r.Spec.RabbitmqDeletionPolicy == "" {
+		r.Spec.RabbitmqDeletionPolicy = DefaultRabbitmqDeletionPolicy
+	}
+
+	if r.Spec.RabbitmqTerminationGracePeriodSeconds == nil {
+		gracePeriod := int64(DefaultRabbitmqTerminationGracePeriodSeconds)
+		r.Spec.RabbitmqTerminationGracePeriodSeconds = &gracePeriod
+	}
+
+	if r.Spec.RabbitmqSSL.Enabled {
+		r.Spec.RabbitmqSSL.Cacertfile = defaultString(r.Spec.RabbitmqSSL.Cacertfile, DefaultRabbitmqSSLCacertfile)
+		r.Spec.RabbitmqSSL.Certfile = defaultString(r.Spec.RabbitmqSSL.Certfile, DefaultRabbitmqSSLCertfile)
+		r.Spec.RabbitmqSSL.Keyfile = defaultString(r.Spec.RabbitmqSSL.Keyfile, DefaultRabbitmqSSLKeyfile)
+		r.Spec.RabbitmqSSL.Verify = defaultString(r.Spec.RabbitmqSSL.Verify, DefaultRabbitmqSSLVerify)
+		if r.Spec.RabbitmqSSL.IssuerRef != nil {
+			r.Spec.RabbitmqSSL.ExitingSecret = defaultString(r.Spec.RabbitmqSSL.ExitingSecret, r.Name+"-tls")
+			r.Spec.RabbitmqSSL.IssuerRef.Kind = defaultString(r.Spec.RabbitmqSSL.IssuerRef.Kind, DefaultRabbitmqIssuerKind)
+			r.Spec.RabbitmqSSL.IssuerRef.Group = defaultString(r.Spec.RabbitmqSSL.IssuerRef.Group, DefaultRabbitmqIssuerGroup)
+		}
+	}
+
+	r.Spec.RabbitmqPodRequests = defaultResource(r.Spec.RabbitmqPodRequests, corev1.ResourceCPU, DefaultRabbitmqPodCPURequest)
+	r.Spec.RabbitmqPodRequests = defaultResource(r.Spec.RabbitmqPodRequests, corev1.ResourceMemory, DefaultRabbitmqPodMemoryRequest)
+	r.Spec.RabbitmqPodLimits = defaultResource(r.Spec.RabbitmqPodLimits, corev1.ResourceCPU, DefaultRabbitmqPodCPULimit)
+	r.Spec.RabbitmqPodLimits = defaultResource(r.Spec.RabbitmqPodLimits, corev1.ResourceMemory, DefaultRabbitmqPodMemoryLimit)
+}
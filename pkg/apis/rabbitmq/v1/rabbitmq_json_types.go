@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RabbitmqArguments optional x-arguments of queues, exchanges and bindings.
+// Values are any JSON: strings, numbers, booleans, lists and objects
+// +k8s:deepcopy-gen=false
+type RabbitmqArguments map[string]interface{}
+
+// DeepCopyInto copies JSON values of arguments, values must be types produced by json.Unmarshal or int64
+func (in RabbitmqArguments) DeepCopyInto(out *RabbitmqArguments) {
+	*out = RabbitmqArguments(runtime.DeepCopyJSON(in))
+}
+
+// DeepCopy copies arguments
+func (in RabbitmqArguments) DeepCopy() RabbitmqArguments {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqArguments)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// RabbitmqPolicyDefinition policy keys like ha-mode, message-ttl, dead-letter-exchange or queue-leader-locator.
+// Any keys are passed to rabbitmq, known keys are validated by RabbitmqPolicyDefinitionKeys
+// +k8s:deepcopy-gen=false
+type RabbitmqPolicyDefinition map[string]interface{}
+
+// DeepCopyInto copies JSON values of definition, values must be types produced by json.Unmarshal or int64
+func (in RabbitmqPolicyDefinition) DeepCopyInto(out *RabbitmqPolicyDefinition) {
+	*out = RabbitmqPolicyDefinition(runtime.DeepCopyJSON(in))
+}
+
+// DeepCopy copies definition
+func (in RabbitmqPolicyDefinition) DeepCopy() RabbitmqPolicyDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqPolicyDefinition)
+	in.DeepCopyInto(out)
+	return *out
+}
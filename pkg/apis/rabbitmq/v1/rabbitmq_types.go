@@ -11,21 +11,46 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // RabbitmqImage Sets image url and tag
+// +k8s:openapi-gen=true
 type RabbitmqImage struct {
 	Name string `json:"name"`
 	Tag  string `json:"tag"`
 }
 
 // RabbitmqSSL sets SSL parameters
+// +k8s:openapi-gen=true
 type RabbitmqSSL struct {
-	Enabled       bool   `json:"enabled"`
+	Enabled bool `json:"enabled"`
+	// secret with CA, certificate and key, it is mounted into rabbitmq container
 	ExitingSecret string `json:"exitingSecret,omitempty"`
-	Cacertfile    string `json:"cacertfile,omitempty"`
-	Certfile      string `json:"certfile,omitempty"`
-	Keyfile       string `json:"keyfile,omitempty"`
+	// keys of the secret, ca.crt, tls.crt and tls.key by default
+	Cacertfile string `json:"cacertfile,omitempty"`
+	Certfile   string `json:"certfile,omitempty"`
+	Keyfile    string `json:"keyfile,omitempty"`
+	// ssl_options.verify, verify_none or verify_peer
+	Verify string `json:"verify,omitempty"`
+	// ssl_options.fail_if_no_peer_cert, requires verify_peer
+	FailIfNoPeerCert bool `json:"failIfNoPeerCert,omitempty"`
+	// disables plaintext AMQP listener on 5672
+	DisablePlaintext bool `json:"disablePlaintext,omitempty"`
+	// erlang distribution between nodes and CLI tools over TLS with the same certificate, requires verify of peers
+	InterNodeTLS bool `json:"interNodeTLS,omitempty"`
+	// cert-manager issuer, operator creates Certificate which writes exitingSecret
+	IssuerRef *RabbitmqIssuerRef `json:"issuerRef,omitempty"`
+}
+
+// RabbitmqIssuerRef cert-manager Issuer or ClusterIssuer of server certificate
+// +k8s:openapi-gen=true
+type RabbitmqIssuerRef struct {
+	Name string `json:"name"`
+	// Issuer by default
+	Kind string `json:"kind,omitempty"`
+	// cert-manager.io by default, set for external issuers
+	Group string `json:"group,omitempty"`
 }
 
 // RabbitmqAuth auth config
+// +k8s:openapi-gen=true
 type RabbitmqAuth struct {
 	Enabled bool `json:"enabled"`
 	// +kubebuilder:validation:UniqueItems=true
@@ -33,6 +58,7 @@ type RabbitmqAuth struct {
 }
 
 // RabbitmqPolicy type
+// +k8s:openapi-gen=true
 type RabbitmqPolicy struct {
 	Vhost      string                   `json:"vhost,omitempty"`
 	Name       string                   `json:"name"`
@@ -40,18 +66,16 @@ type RabbitmqPolicy struct {
 	Definition RabbitmqPolicyDefinition `json:"definition"`
 	Priority   int64                    `json:"priority"`
 	ApplyTo    string                   `json:"apply-to"`
+
+	// take over policy with the same vhost and name created outside of operator
+	Adopt bool `json:"adopt,omitempty"`
 }
 
-// RabbitmqPolicyDefinition type
-type RabbitmqPolicyDefinition struct {
-	FederationUpstreamSet string `json:"federation-upstream-set,omitempty"`
-	HaMode                string `json:"ha-mode,omitempty"`
-	HaParams              int    `json:"ha-params,omitempty"`
-	HaSyncMode            string `json:"ha-sync-mode,omitempty"`
-	Expires               int    `json:"expires,omitempty"`
-	MessageTTL            int    `json:"message-ttl,omitempty"`
-	MaxLen                int    `json:"max-length,omitempty"`
-	MaxLenBytes           int    `json:"max-length-bytes,omitempty"`
+// RabbitmqPolicyReference vhost and name of policy created by operator
+// +k8s:openapi-gen=true
+type RabbitmqPolicyReference struct {
+	Vhost string `json:"vhost"`
+	Name  string `json:"name"`
 }
 
 // RabbitmqSpec defines the desired state of Rabbitmq
@@ -65,7 +89,7 @@ type RabbitmqSpec struct {
 	// else if replicas = 1, maxU = 1
 	RabbitmqPdb v1beta1.PodDisruptionBudget `json:"pdb,omitempty"`
 
-	// set default_vhost, if empty falling to "%2f" (/)
+	// set default_vhost, if empty falling to "rabbit"; policies without vhost use it only when it is set, "/" otherwise
 	RabbitmqVhost string `json:"default_vhost,omitempty"`
 
 	// all secrets generated once with CRDs name, but you can set it by hands
@@ -83,8 +107,7 @@ type RabbitmqSpec struct {
 	// Hipe
 	RabbitmqHipeCompile bool `json:"hipe_compile,omitempty"`
 
-	// set SSL settings
-	// TODO: add to template, issue certs with Vault
+	// set SSL settings, AMQPS listens on 5671 and management HTTPS on 15671
 	RabbitmqSSL RabbitmqSSL `json:"cert,omitempty"`
 
 	// TODO: auth mechanisms
@@ -95,6 +118,10 @@ type RabbitmqSpec struct {
 	// set rabbitmq policies
 	RabbitmqPolicies []RabbitmqPolicy `json:"policies"`
 
+	// operator policies cap queue arguments, values can't be overridden by policies or by clients.
+	// Only RabbitmqOperatorPolicyKeys are allowed in definition
+	RabbitmqOperatorPolicies []RabbitmqPolicy `json:"operatorPolicies,omitempty"`
+
 	// load additional plugins
 	RabbitmqPlugins []string `json:"plugins"`
 
@@ -114,8 +141,6 @@ type RabbitmqSpec struct {
 	// TODO: additional labels
 	K8SLabels []metav1.LabelSelector `json:"k8s_labels"`
 
-	// purge all PVC after CR deletion, default false
-	RabbitmqPurgePVC bool `json:"purgePVC,omitempty"`
 	// PersistentVolumeClaim in k8s style
 	RabbitmqVolumeSize resource.Quantity `json:"volume_size"`
 
@@ -135,20 +160,266 @@ type RabbitmqSpec struct {
 	Tolerations      []corev1.Toleration `json:"tolerations"`
 
 	RabbitmqUseServiceMonitor bool `json:"use_service_monitor,omitempty"`
+
+	// how changes of rabbitmq.conf and enabled_plugins reach running pods: RollingRestart restarts pods one by one,
+	// OnNextRestart keeps pods running until they are restarted for other reasons
+	RabbitmqConfigUpdatePolicy string `json:"configUpdatePolicy,omitempty"`
+
+	// what happens to data PVCs when Rabbitmq is deleted: Retain keeps them, Delete removes them,
+	// SnapshotThenDelete removes them after VolumeSnapshots of every PVC are ready
+	RabbitmqDeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// VolumeSnapshotClass of snapshots taken by SnapshotThenDelete, default class of the CSI driver if empty
+	RabbitmqVolumeSnapshotClass string `json:"volumeSnapshotClassName,omitempty"`
+
+	// probes of rabbitmq container, defaults are TCP check of AMQP port for readiness and
+	// rabbitmq-diagnostics ping for liveness with initial delay growing with volume_size
+	RabbitmqReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+	RabbitmqLivenessProbe  *corev1.Probe `json:"livenessProbe,omitempty"`
+
+	// time for preStop hook to wait for quorum queue replicas and drain the node, then rabbitmq is killed
+	RabbitmqTerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// service account password is rotated when it is older, never by default
+	RabbitmqPasswordRotationPeriod *metav1.Duration `json:"rotationPeriod,omitempty"`
+
+	// binding secrets of the instance and its users are copied to these namespaces
+	RabbitmqBindingNamespaces []string `json:"bindingNamespaces,omitempty"`
+}
+
+const (
+	// RabbitmqConfigUpdateRollingRestart pods are restarted when config changes
+	RabbitmqConfigUpdateRollingRestart = "RollingRestart"
+	// RabbitmqConfigUpdateOnNextRestart config is applied when pods are restarted for other reasons
+	RabbitmqConfigUpdateOnNextRestart = "OnNextRestart"
+)
+
+const (
+	// RabbitmqDeletionPolicyRetain data PVCs are kept after Rabbitmq deletion
+	RabbitmqDeletionPolicyRetain = "Retain"
+	// RabbitmqDeletionPolicyDelete data PVCs are deleted with Rabbitmq
+	RabbitmqDeletionPolicyDelete = "Delete"
+	// RabbitmqDeletionPolicySnapshotThenDelete data PVCs are deleted after their VolumeSnapshots are ready
+	RabbitmqDeletionPolicySnapshotThenDelete = "SnapshotThenDelete"
+)
+
+const (
+	// RabbitmqSSLVerifyNone client certificates are not checked
+	RabbitmqSSLVerifyNone = "verify_none"
+	// RabbitmqSSLVerifyPeer client certificates are checked against CA when clients present them
+	RabbitmqSSLVerifyPeer = "verify_peer"
+)
+
+// RabbitmqConditionType is a type of condition reported in RabbitmqStatus
+type RabbitmqConditionType string
+
+const (
+	// RabbitmqConditionAvailable at least one replica is ready to serve clients
+	RabbitmqConditionAvailable RabbitmqConditionType = "Available"
+	// RabbitmqConditionAllReplicasReady every replica of the statefulset is ready
+	RabbitmqConditionAllReplicasReady RabbitmqConditionType = "AllReplicasReady"
+	// RabbitmqConditionClusterFormed every expected node is running and joined the cluster
+	RabbitmqConditionClusterFormed RabbitmqConditionType = "ClusterFormed"
+	// RabbitmqConditionManagementAPIReachable the management API answered the last request
+	RabbitmqConditionManagementAPIReachable RabbitmqConditionType = "ManagementAPIReachable"
+	// RabbitmqConditionPoliciesSynced policies from spec were applied to rabbitmq
+	RabbitmqConditionPoliciesSynced RabbitmqConditionType = "PoliciesSynced"
+	// RabbitmqConditionUsersSynced users from credentials secret were applied to rabbitmq
+	RabbitmqConditionUsersSynced RabbitmqConditionType = "UsersSynced"
+	// RabbitmqConditionConfigApplied pods were started with the current config
+	RabbitmqConditionConfigApplied RabbitmqConditionType = "ConfigApplied"
+	// RabbitmqConditionRolloutComplete all pods run the current statefulset revision
+	RabbitmqConditionRolloutComplete RabbitmqConditionType = "RolloutComplete"
+	// RabbitmqConditionScalingComplete statefulset replicas and quorum queue membership match spec replicas
+	RabbitmqConditionScalingComplete RabbitmqConditionType = "ScalingComplete"
+	// RabbitmqConditionVolumesExpanded data volumes have capacity of spec volume_size
+	RabbitmqConditionVolumesExpanded RabbitmqConditionType = "VolumesExpanded"
+	// RabbitmqConditionCertificateReady cert-manager issued server certificate
+	RabbitmqConditionCertificateReady RabbitmqConditionType = "CertificateReady"
+	// RabbitmqConditionReconcileSuccess the last reconcile finished without errors
+	RabbitmqConditionReconcileSuccess RabbitmqConditionType = "ReconcileSuccess"
+)
+
+// RabbitmqRolloutStatus progress of pod by pod restart, next pod is restarted when the cluster is healthy again
+// +k8s:openapi-gen=true
+type RabbitmqRolloutStatus struct {
+	// statefulset revision pods are restarted to
+	Revision string `json:"revision"`
+	// pods running the revision
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+	// pod deleted last
+	RestartingPod string `json:"restartingPod,omitempty"`
+	// what rollout is waiting for
+	Message string `json:"message,omitempty"`
+}
+
+// RabbitmqScaleDownPhase is a step of node removal
+type RabbitmqScaleDownPhase string
+
+const (
+	// RabbitmqScaleDownDraining node is put into maintenance mode, clients and queue leaders are moved to other nodes
+	RabbitmqScaleDownDraining RabbitmqScaleDownPhase = "Draining"
+	// RabbitmqScaleDownShrinkingQueues node is removed from quorum queue members
+	RabbitmqScaleDownShrinkingQueues RabbitmqScaleDownPhase = "ShrinkingQueues"
+	// RabbitmqScaleDownForgettingNode node is stopped and removed from cluster
+	RabbitmqScaleDownForgettingNode RabbitmqScaleDownPhase = "ForgettingNode"
+	// RabbitmqScaleDownRemovingPod statefulset replicas are reduced
+	RabbitmqScaleDownRemovingPod RabbitmqScaleDownPhase = "RemovingPod"
+)
+
+// RabbitmqScaleDownStatus progress of removing the highest ordinal node, statefulset replicas are reduced after the node left cluster
+// +k8s:openapi-gen=true
+type RabbitmqScaleDownStatus struct {
+	Pod  string `json:"pod"`
+	Node string `json:"node"`
+	// the step to run next
+	Phase RabbitmqScaleDownPhase `json:"phase"`
+	// error of the last attempt
+	Message string `json:"message,omitempty"`
+}
+
+// RabbitmqVolumeExpansionStatus progress of data volumes resize after volume_size was increased
+// +k8s:openapi-gen=true
+type RabbitmqVolumeExpansionStatus struct {
+	// requested size of volumes
+	Size resource.Quantity `json:"size"`
+	// volumes which have requested capacity
+	ExpandedVolumes int32 `json:"expandedVolumes"`
+	Volumes         int32 `json:"volumes"`
+	// what expansion is waiting for
+	Message string `json:"message,omitempty"`
+}
+
+// RabbitmqRotationPhase is a step of service account password or erlang cookie rotation
+type RabbitmqRotationPhase string
+
+const (
+	// RabbitmqRotationCreatingUser new service account user is created with tags and permissions of the current one
+	RabbitmqRotationCreatingUser RabbitmqRotationPhase = "CreatingUser"
+	// RabbitmqRotationSwitchingClients secret has new credentials, pods are restarted to switch exporter to them
+	RabbitmqRotationSwitchingClients RabbitmqRotationPhase = "SwitchingClients"
+	// RabbitmqRotationDeletingUser previous service account user is deleted
+	RabbitmqRotationDeletingUser RabbitmqRotationPhase = "DeletingUser"
+	// RabbitmqRotationStoppingNodes nodes are stopped from the highest ordinal, so node 0 stops last and boots first
+	RabbitmqRotationStoppingNodes RabbitmqRotationPhase = "StoppingNodes"
+	// RabbitmqRotationRestartingCluster secret has new cookie or pod template has new inter-node TLS setting,
+	// all pods are deleted at once and cluster is formed again
+	RabbitmqRotationRestartingCluster RabbitmqRotationPhase = "RestartingCluster"
+)
+
+// RabbitmqCredentialsStatus rotation of service account password and erlang cookie
+// +k8s:openapi-gen=true
+type RabbitmqCredentialsStatus struct {
+	LastPasswordRotation *metav1.Time `json:"lastPasswordRotation,omitempty"`
+	LastCookieRotation   *metav1.Time `json:"lastCookieRotation,omitempty"`
+	// nodes were stopped by the last full restart, pods created before are restarted
+	LastClusterRestart *metav1.Time `json:"lastClusterRestart,omitempty"`
+	// values of rotate annotations handled last
+	PasswordRotationRequest string `json:"passwordRotationRequest,omitempty"`
+	CookieRotationRequest   string `json:"cookieRotationRequest,omitempty"`
+	// step of rotation in progress, empty when nothing is rotated. Cookie phases are also used by a full restart
+	// switching interNodeTLS
+	PasswordRotationPhase RabbitmqRotationPhase `json:"passwordRotationPhase,omitempty"`
+	CookieRotationPhase   RabbitmqRotationPhase `json:"cookieRotationPhase,omitempty"`
+	// what rotation is waiting for
+	Message string `json:"message,omitempty"`
+}
+
+// RabbitmqCertificateStatus server certificate issued by cert-manager
+// +k8s:openapi-gen=true
+type RabbitmqCertificateStatus struct {
+	// Certificate and its secret
+	Name       string `json:"name"`
+	SecretName string `json:"secretName"`
+	// expiration of the issued certificate
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	// time cert-manager renews the certificate at
+	RenewalTime *metav1.Time `json:"renewalTime,omitempty"`
+}
+
+// RabbitmqCondition describes the state of one aspect of the cluster at a certain point
+// +k8s:openapi-gen=true
+type RabbitmqCondition struct {
+	Type   RabbitmqConditionType  `json:"type"`
+	Status corev1.ConditionStatus `json:"status"`
+	// last time the condition changed its status
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// machine readable reason of the last transition
+	Reason string `json:"reason,omitempty"`
+	// human readable details
+	Message string `json:"message,omitempty"`
+}
+
+// RabbitmqNodeStatus node state as reported by /api/nodes
+// +k8s:openapi-gen=true
+type RabbitmqNodeStatus struct {
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+	// disc or ram
+	Type string `json:"type,omitempty"`
+	// nodes this node can't reach, not empty in case of network partition
+	Partitions []string `json:"partitions,omitempty"`
 }
 
 // RabbitmqStatus defines the observed state of Rabbitmq
 // +k8s:openapi-gen=true
 type RabbitmqStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
-	// Add custom validation using kubebuilder tags: https://book.kubebuilder.io/beyond_basics/generating_crd.html
+	// generation of the spec the status was calculated for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ready pods of the statefulset
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// running versions, taken from /api/overview
+	RabbitmqVersion string `json:"rabbitmqVersion,omitempty"`
+	ErlangVersion   string `json:"erlangVersion,omitempty"`
+
+	// cluster members, taken from /api/nodes
+	Nodes []RabbitmqNodeStatus `json:"nodes,omitempty"`
+
+	// policies owned by operator, only these are removed when missing in spec
+	Policies []RabbitmqPolicyReference `json:"policies,omitempty"`
+
+	// operator policies owned by operator
+	OperatorPolicies []RabbitmqPolicyReference `json:"operatorPolicies,omitempty"`
+
+	// policies are recorded as owned, false for clusters managed by operator versions which did not record them,
+	// their policies listed in spec are taken over on first sync
+	PoliciesTracked bool `json:"policiesTracked,omitempty"`
+
+	// restart of pods with new statefulset revision, empty when all pods are up to date
+	Rollout *RabbitmqRolloutStatus `json:"rollout,omitempty"`
+
+	// removal of node on scale down, empty when no node is being removed
+	ScaleDown *RabbitmqScaleDownStatus `json:"scaleDown,omitempty"`
+
+	// resize of data volumes, empty when all volumes have requested size
+	VolumeExpansion *RabbitmqVolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+
+	// server certificate issued by cert-manager, empty without cert.issuerRef
+	Certificate *RabbitmqCertificateStatus `json:"certificate,omitempty"`
+
+	// rotation of service account password and erlang cookie
+	Credentials *RabbitmqCredentialsStatus `json:"credentials,omitempty"`
+
+	// replicas quorum queue membership was grown to, new nodes become members of existing quorum queues on scale up
+	QueueMembershipReplicas int32 `json:"queueMembershipReplicas,omitempty"`
+
+	// namespaces with copies of binding secrets, only these are searched for copies to update or delete
+	BindingNamespaces []string `json:"bindingNamespaces,omitempty"`
+
+	Conditions []RabbitmqCondition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // Rabbitmq is the Schema for the rabbitmqs API
 // +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.rabbitmqVersion"
+// +kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type=='Available')].status"
+// +kubebuilder:printcolumn:name="Synced",type="string",JSONPath=".status.conditions[?(@.type=='ReconcileSuccess')].status"
 type Rabbitmq struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,482 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// SkipPluginValidationAnnotation allows plugins missing in KnownRabbitmqPlugins, for custom images
+const SkipPluginValidationAnnotation = "rabbitmq.improvado.io/skip-plugin-validation"
+
+// RabbitmqPartitionHandlingModes values supported by cluster_partition_handling
+var RabbitmqPartitionHandlingModes = []string{"ignore", "autoheal", "pause_minority", "pause_if_all_down"}
+
+// RabbitmqConfigUpdatePolicies values supported by configUpdatePolicy
+var RabbitmqConfigUpdatePolicies = []string{RabbitmqConfigUpdateRollingRestart, RabbitmqConfigUpdateOnNextRestart}
+
+// RabbitmqDeletionPolicies values supported by deletionPolicy
+var RabbitmqDeletionPolicies = []string{RabbitmqDeletionPolicyRetain, RabbitmqDeletionPolicyDelete, RabbitmqDeletionPolicySnapshotThenDelete}
+
+// RabbitmqSSLVerifyModes values supported by cert.verify
+var RabbitmqSSLVerifyModes = []string{RabbitmqSSLVerifyNone, RabbitmqSSLVerifyPeer}
+
+// RabbitmqIssuerKinds cert-manager issuer kinds
+var RabbitmqIssuerKinds = []string{"Issuer", "ClusterIssuer"}
+
+// KnownRabbitmqPlugins plugins shipped with rabbitmq and widely used community plugins
+var KnownRabbitmqPlugins = []string{
+	"rabbitmq_amqp1_0",
+	"rabbitmq_auth_backend_cache",
+	"rabbitmq_auth_backend_http",
+	"rabbitmq_auth_backend_ldap",
+	"rabbitmq_auth_backend_oauth2",
+	"rabbitmq_auth_mechanism_ssl",
+	"rabbitmq_consistent_hash_exchange",
+	"rabbitmq_event_exchange",
+	"rabbitmq_federation",
+	"rabbitmq_federation_management",
+	"rabbitmq_jms_topic_exchange",
+	"rabbitmq_management",
+	"rabbitmq_management_agent",
+	"rabbitmq_mqtt",
+	"rabbitmq_peer_discovery_aws",
+	"rabbitmq_peer_discovery_common",
+	"rabbitmq_peer_discovery_consul",
+	"rabbitmq_peer_discovery_etcd",
+	"rabbitmq_peer_discovery_k8s",
+	"rabbitmq_prometheus",
+	"rabbitmq_random_exchange",
+	"rabbitmq_recent_history_exchange",
+	"rabbitmq_sharding",
+	"rabbitmq_shovel",
+	"rabbitmq_shovel_management",
+	"rabbitmq_stomp",
+	"rabbitmq_stream",
+	"rabbitmq_stream_management",
+	"rabbitmq_top",
+	"rabbitmq_tracing",
+	"rabbitmq_trust_store",
+	"rabbitmq_web_dispatch",
+	"rabbitmq_web_mqtt",
+	"rabbitmq_web_mqtt_examples",
+	"rabbitmq_web_stomp",
+	"rabbitmq_web_stomp_examples",
+	// community
+	"rabbitmq_delayed_message_exchange",
+	"rabbitmq_message_timestamp",
+	"rabbitmq_message_deduplication",
+}
+
+// RabbitmqPolicyApplyTo values supported by apply-to of policy
+var RabbitmqPolicyApplyTo = []string{"all", "queues", "exchanges", "classic_queues", "quorum_queues", "streams"}
+
+// Kinds of policy definition values
+const (
+	PolicyValueString  = "string"
+	PolicyValueInteger = "integer"
+	PolicyValueList    = "list"
+)
+
+// RabbitmqPolicyDefinitionKey expected value of known policy definition key
+type RabbitmqPolicyDefinitionKey struct {
+	Kind string
+	// allowed values of string key, any value if empty
+	Values []string
+}
+
+// RabbitmqPolicyDefinitionKeys known policy keys of rabbitmq and bundled plugins, other keys are passed without validation.
+// ha-params is validated separately, it is an integer or a list of nodes depending on ha-mode
+var RabbitmqPolicyDefinitionKeys = map[string]RabbitmqPolicyDefinitionKey{
+	"alternate-exchange":            {Kind: PolicyValueString},
+	"dead-letter-exchange":          {Kind: PolicyValueString},
+	"dead-letter-routing-key":       {Kind: PolicyValueString},
+	"dead-letter-strategy":          {Kind: PolicyValueString, Values: []string{"at-most-once", "at-least-once"}},
+	"delivery-limit":                {Kind: PolicyValueInteger},
+	"expires":                       {Kind: PolicyValueInteger},
+	"federation-upstream":           {Kind: PolicyValueString},
+	"federation-upstream-set":       {Kind: PolicyValueString},
+	"ha-mode":                       {Kind: PolicyValueString, Values: []string{"all", "exactly", "nodes"}},
+	"ha-promote-on-failure":         {Kind: PolicyValueString, Values: []string{"always", "when-synced"}},
+	"ha-promote-on-shutdown":        {Kind: PolicyValueString, Values: []string{"always", "when-synced"}},
+	"ha-sync-batch-size":            {Kind: PolicyValueInteger},
+	"ha-sync-mode":                  {Kind: PolicyValueString, Values: []string{"manual", "automatic"}},
+	"max-age":                       {Kind: PolicyValueString},
+	"max-in-memory-bytes":           {Kind: PolicyValueInteger},
+	"max-in-memory-length":          {Kind: PolicyValueInteger},
+	"max-length":                    {Kind: PolicyValueInteger},
+	"max-length-bytes":              {Kind: PolicyValueInteger},
+	"message-ttl":                   {Kind: PolicyValueInteger},
+	"overflow":                      {Kind: PolicyValueString, Values: []string{"drop-head", "reject-publish", "reject-publish-dlx"}},
+	"queue-leader-locator":          {Kind: PolicyValueString, Values: []string{"client-local", "balanced", "random", "least-leaders"}},
+	"queue-master-locator":          {Kind: PolicyValueString, Values: []string{"min-masters", "client-local", "random"}},
+	"queue-mode":                    {Kind: PolicyValueString, Values: []string{"default", "lazy"}},
+	"queue-version":                 {Kind: PolicyValueInteger},
+	"stream-max-segment-size-bytes": {Kind: PolicyValueInteger},
+	"target-group-size":             {Kind: PolicyValueInteger},
+}
+
+// RabbitmqOperatorPolicyKeys keys allowed in operator policy definition
+var RabbitmqOperatorPolicyKeys = []string{
+	"delivery-limit",
+	"expires",
+	"max-in-memory-bytes",
+	"max-in-memory-length",
+	"max-length",
+	"max-length-bytes",
+	"message-ttl",
+	"queue-version",
+	"target-group-size",
+}
+
+// RabbitmqOperatorPolicyApplyTo operator policies are applied only to queues
+var RabbitmqOperatorPolicyApplyTo = []string{"queues", "classic_queues", "quorum_queues", "streams"}
+
+// stream max-age: number with Y, M, D, h, m or s unit
+var maxAgeRegexp = regexp.MustCompile(`^[0-9]+[YMDhms]$`)
+
+// absolute watermark in rabbitmq.conf format: 1024, 512MiB, 1GB, 256M
+var watermarkRegexp = regexp.MustCompile(`^([0-9]+)([kKmMgGtT]?)(i?[bB])?$`)
+
+// ParseRabbitmqMemory converts rabbitmq.conf memory value to bytes.
+// Single letter and IEC (KiB, MiB) units are powers of 2, SI units (kB, MB) are powers of 10
+func ParseRabbitmqMemory(value string) (int64, error) {
+	match := watermarkRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("invalid memory value %q", value)
+	}
+
+	number, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	base := int64(1024)
+	if strings.EqualFold(match[3], "b") {
+		base = 1000
+	}
+
+	switch strings.ToLower(match[2]) {
+	case "k":
+		return number * base, nil
+	case "m":
+		return number * base * base, nil
+	case "g":
+		return number * base * base * base, nil
+	case "t":
+		return number * base * base * base * base, nil
+	}
+	return number, nil
+}
+
+func containsItem(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// policyInteger returns integer value of JSON number decoded into interface{}
+func policyInteger(value interface{}) (int64, bool) {
+	switch number := value.(type) {
+	case int64:
+		return number, true
+	case float64:
+		if number == math.Trunc(number) {
+			return int64(number), true
+		}
+	case json.Number:
+		integer, err := number.Int64()
+		return integer, err == nil
+	}
+	return 0, false
+}
+
+func sortedKeys(definition RabbitmqPolicyDefinition) []string {
+	keys := make([]string, 0, len(definition))
+	for key := range definition {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidatePolicyDefinition checks types and values of known keys of policy definition
+func ValidatePolicyDefinition(definitionPath *field.Path, definition RabbitmqPolicyDefinition) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(definition) == 0 {
+		return append(allErrs, field.Required(definitionPath, "policy definition must have at least one key"))
+	}
+
+	for _, key := range sortedKeys(definition) {
+		value := definition[key]
+		keyPath := definitionPath.Key(key)
+		known, ok := RabbitmqPolicyDefinitionKeys[key]
+		if !ok {
+			continue
+		}
+
+		switch known.Kind {
+		case PolicyValueString:
+			stringValue, isString := value.(string)
+			if !isString {
+				allErrs = append(allErrs, field.Invalid(keyPath, value, "must be a string"))
+			} else if len(known.Values) > 0 && !containsItem(known.Values, stringValue) {
+				allErrs = append(allErrs, field.NotSupported(keyPath, stringValue, known.Values))
+			}
+		case PolicyValueInteger:
+			if integer, isInteger := policyInteger(value); !isInteger || integer < 0 {
+				allErrs = append(allErrs, field.Invalid(keyPath, value, "must be a non-negative integer"))
+			}
+		}
+	}
+
+	if maxAge, ok := definition["max-age"].(string); ok && !maxAgeRegexp.MatchString(maxAge) {
+		allErrs = append(allErrs, field.Invalid(definitionPath.Key("max-age"), maxAge, "must be a number with Y, M, D, h, m or s unit"))
+	}
+
+	if haParams, ok := definition["ha-params"]; ok {
+		haParamsPath := definitionPath.Key("ha-params")
+		switch definition["ha-mode"] {
+		case "exactly":
+			if integer, isInteger := policyInteger(haParams); !isInteger || integer < 1 {
+				allErrs = append(allErrs, field.Invalid(haParamsPath, haParams, "must be a positive integer for ha-mode exactly"))
+			}
+		case "nodes":
+			nodes, isList := haParams.([]interface{})
+			if !isList {
+				allErrs = append(allErrs, field.Invalid(haParamsPath, haParams, "must be a list of node names for ha-mode nodes"))
+			}
+			for i, node := range nodes {
+				if _, isString := node.(string); !isString {
+					allErrs = append(allErrs, field.Invalid(haParamsPath.Index(i), node, "must be a string"))
+				}
+			}
+		default:
+			allErrs = append(allErrs, field.Invalid(haParamsPath, haParams, "is only allowed with ha-mode exactly or nodes"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateOperatorPolicy checks operator policy, its definition has only queue limits
+func ValidateOperatorPolicy(policyPath *field.Path, policy RabbitmqPolicy) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if policy.Name == "" {
+		allErrs = append(allErrs, field.Required(policyPath.Child("name"), "policy name must be set"))
+	}
+	if policy.ApplyTo != "" && !containsItem(RabbitmqOperatorPolicyApplyTo, policy.ApplyTo) {
+		allErrs = append(allErrs, field.NotSupported(policyPath.Child("apply-to"), policy.ApplyTo, RabbitmqOperatorPolicyApplyTo))
+	}
+
+	definitionPath := policyPath.Child("definition")
+	for _, key := range sortedKeys(policy.Definition) {
+		if !containsItem(RabbitmqOperatorPolicyKeys, key) {
+			allErrs = append(allErrs, field.NotSupported(definitionPath.Key(key), key, RabbitmqOperatorPolicyKeys))
+		}
+	}
+
+	return append(allErrs, ValidatePolicyDefinition(definitionPath, policy.Definition)...)
+}
+
+// ValidatePolicy checks apply-to and definition of policy
+func ValidatePolicy(policyPath *field.Path, policy RabbitmqPolicy) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if policy.Name == "" {
+		allErrs = append(allErrs, field.Required(policyPath.Child("name"), "policy name must be set"))
+	}
+	if policy.ApplyTo != "" && !containsItem(RabbitmqPolicyApplyTo, policy.ApplyTo) {
+		allErrs = append(allErrs, field.NotSupported(policyPath.Child("apply-to"), policy.ApplyTo, RabbitmqPolicyApplyTo))
+	}
+
+	return append(allErrs, ValidatePolicyDefinition(policyPath.Child("definition"), policy.Definition)...)
+}
+
+// validateSSL checks TLS settings, listeners without certificate would prevent rabbitmq from starting
+func validateSSL(ssl RabbitmqSSL, sslPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !ssl.Enabled {
+		if ssl.DisablePlaintext {
+			allErrs = append(allErrs, field.Invalid(sslPath.Child("disablePlaintext"), ssl.DisablePlaintext, "requires enabled TLS"))
+		}
+		if ssl.InterNodeTLS {
+			allErrs = append(allErrs, field.Invalid(sslPath.Child("interNodeTLS"), ssl.InterNodeTLS, "requires enabled TLS"))
+		}
+		return allErrs
+	}
+
+	if ssl.ExitingSecret == "" && ssl.IssuerRef == nil {
+		allErrs = append(allErrs, field.Required(sslPath.Child("exitingSecret"), "secret with certificate or issuerRef must be set when TLS is enabled"))
+	}
+	if ssl.IssuerRef != nil {
+		issuerPath := sslPath.Child("issuerRef")
+		if ssl.IssuerRef.Name == "" {
+			allErrs = append(allErrs, field.Required(issuerPath.Child("name"), "issuer name must be set"))
+		}
+		if ssl.IssuerRef.Group == DefaultRabbitmqIssuerGroup && ssl.IssuerRef.Kind != "" && !containsItem(RabbitmqIssuerKinds, ssl.IssuerRef.Kind) {
+			allErrs = append(allErrs, field.NotSupported(issuerPath.Child("kind"), ssl.IssuerRef.Kind, RabbitmqIssuerKinds))
+		}
+	}
+	if ssl.Verify != "" && !containsItem(RabbitmqSSLVerifyModes, ssl.Verify) {
+		allErrs = append(allErrs, field.NotSupported(sslPath.Child("verify"), ssl.Verify, RabbitmqSSLVerifyModes))
+	}
+	if ssl.FailIfNoPeerCert && ssl.Verify != RabbitmqSSLVerifyPeer {
+		allErrs = append(allErrs, field.Invalid(sslPath.Child("failIfNoPeerCert"), ssl.FailIfNoPeerCert, "requires verify "+RabbitmqSSLVerifyPeer))
+	}
+	return allErrs
+}
+
+// changedErrors returns errors of new spec which old spec didn't have
+func changedErrors(allErrs field.ErrorList, oldErrs field.ErrorList) field.ErrorList {
+	existing := map[string]bool{}
+	for _, err := range oldErrs {
+		existing[err.Error()] = true
+	}
+
+	var changed field.ErrorList
+	for _, err := range allErrs {
+		if !existing[err.Error()] {
+			changed = append(changed, err)
+		}
+	}
+	return changed
+}
+
+func (r *Rabbitmq) validationError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: SchemeGroupVersion.Group, Kind: "Rabbitmq"}, r.Name, allErrs)
+}
+
+func (r *Rabbitmq) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.K8SImage.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("image", "name"), "image name must be set"))
+	}
+	if r.Spec.K8SImage.Tag == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("image", "tag"), "image tag must be set"))
+	}
+
+	if r.Spec.RabbitmqClusterPartitionHandling != "" && !containsItem(RabbitmqPartitionHandlingModes, r.Spec.RabbitmqClusterPartitionHandling) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("cluster_partition_handling"), r.Spec.RabbitmqClusterPartitionHandling, RabbitmqPartitionHandlingModes))
+	}
+
+	if r.Spec.RabbitmqConfigUpdatePolicy != "" && !containsItem(RabbitmqConfigUpdatePolicies, r.Spec.RabbitmqConfigUpdatePolicy) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("configUpdatePolicy"), r.Spec.RabbitmqConfigUpdatePolicy, RabbitmqConfigUpdatePolicies))
+	}
+
+	if r.Spec.RabbitmqDeletionPolicy != "" && !containsItem(RabbitmqDeletionPolicies, r.Spec.RabbitmqDeletionPolicy) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("deletionPolicy"), r.Spec.RabbitmqDeletionPolicy, RabbitmqDeletionPolicies))
+	}
+
+	if r.Spec.RabbitmqTerminationGracePeriodSeconds != nil && *r.Spec.RabbitmqTerminationGracePeriodSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("terminationGracePeriodSeconds"), *r.Spec.RabbitmqTerminationGracePeriodSeconds, "must be greater than or equal to 0"))
+	}
+
+	if r.Spec.RabbitmqPasswordRotationPeriod != nil && r.Spec.RabbitmqPasswordRotationPeriod.Duration < time.Hour {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("rotationPeriod"), r.Spec.RabbitmqPasswordRotationPeriod.Duration.String(), "must be at least 1h"))
+	}
+
+	for i, namespace := range r.Spec.RabbitmqBindingNamespaces {
+		for _, message := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("bindingNamespaces").Index(i), namespace, message))
+		}
+	}
+
+	allErrs = append(allErrs, validateSSL(r.Spec.RabbitmqSSL, specPath.Child("cert"))...)
+
+	if r.Spec.RabbitmqMemoryHighWatermark != "" {
+		watermarkPath := specPath.Child("memory_high_watermark")
+		watermark, err := ParseRabbitmqMemory(r.Spec.RabbitmqMemoryHighWatermark)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(watermarkPath, r.Spec.RabbitmqMemoryHighWatermark, err.Error()))
+		} else if memoryLimit, ok := r.Spec.RabbitmqPodLimits[corev1.ResourceMemory]; ok && watermark > memoryLimit.Value() {
+			allErrs = append(allErrs, field.Invalid(watermarkPath, r.Spec.RabbitmqMemoryHighWatermark, "watermark is larger than pod memory limit "+memoryLimit.String()))
+		}
+	}
+
+	// policies without vhost are applied to default_vhost or "/"
+	defaultVhost := r.PolicyVhost()
+	policiesSeen := map[string]bool{}
+	for i, policy := range r.Spec.RabbitmqPolicies {
+		allErrs = append(allErrs, ValidatePolicy(specPath.Child("policies").Index(i), policy)...)
+
+		policyVhost := policy.Vhost
+		if policyVhost == "" {
+			policyVhost = defaultVhost
+		}
+		if policiesSeen[policyVhost+"/"+policy.Name] {
+			allErrs = append(allErrs, field.Duplicate(specPath.Child("policies").Index(i).Child("name"), policy.Name))
+		}
+		policiesSeen[policyVhost+"/"+policy.Name] = true
+	}
+
+	operatorPoliciesSeen := map[string]bool{}
+	for i, policy := range r.Spec.RabbitmqOperatorPolicies {
+		allErrs = append(allErrs, ValidateOperatorPolicy(specPath.Child("operatorPolicies").Index(i), policy)...)
+
+		policyVhost := policy.Vhost
+		if policyVhost == "" {
+			policyVhost = defaultVhost
+		}
+		if operatorPoliciesSeen[policyVhost+"/"+policy.Name] {
+			allErrs = append(allErrs, field.Duplicate(specPath.Child("operatorPolicies").Index(i).Child("name"), policy.Name))
+		}
+		operatorPoliciesSeen[policyVhost+"/"+policy.Name] = true
+	}
+
+	if _, skip := r.Annotations[SkipPluginValidationAnnotation]; !skip {
+		for i, plugin := range r.Spec.RabbitmqPlugins {
+			if !containsItem(KnownRabbitmqPlugins, plugin) {
+				allErrs = append(allErrs, field.NotFound(specPath.Child("plugins").Index(i), plugin))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateCreate checks spec of a new Rabbitmq
+func (r *Rabbitmq) ValidateCreate() error {
+	return r.validationError(r.validateSpec())
+}
+
+// ValidateUpdate checks changed fields of spec and changes not allowed for existing Rabbitmq.
+// Errors which old spec already had are skipped, so objects created before validation
+// can still be updated and deleted
+func (r *Rabbitmq) ValidateUpdate(old *Rabbitmq) error {
+	// removing finalizers and other metadata updates don't touch spec
+	if r.DeletionTimestamp != nil || reflect.DeepEqual(old.Spec, r.Spec) {
+		return nil
+	}
+
+	allErrs := changedErrors(r.validateSpec(), old.validateSpec())
+
+	if r.Spec.RabbitmqVolumeSize.Cmp(old.Spec.RabbitmqVolumeSize) < 0 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "volume_size"), "volume size can't be decreased from "+old.Spec.RabbitmqVolumeSize.String()))
+	}
+
+	return r.validationError(allErrs)
+}
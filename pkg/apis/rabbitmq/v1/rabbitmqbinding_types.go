@@ -0,0 +1,77 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RabbitmqBindingDestinationTypes queue or exchange (exchange to exchange binding)
+var RabbitmqBindingDestinationTypes = []string{"queue", "exchange"}
+
+// RabbitmqBindingSpec defines the desired state of RabbitmqBinding
+// +k8s:openapi-gen=true
+type RabbitmqBindingSpec struct {
+	// name of Rabbitmq resource in the same namespace
+	RabbitmqInstance string `json:"rabbitmq"`
+
+	// vhost of binding, if empty falling to default vhost of Rabbitmq
+	Vhost string `json:"vhost,omitempty"`
+
+	// source exchange name
+	Source string `json:"source"`
+
+	// destination queue or exchange name
+	Destination string `json:"destination"`
+
+	// queue or exchange, default queue
+	DestinationType string `json:"destinationType,omitempty"`
+
+	RoutingKey string            `json:"routingKey,omitempty"`
+	Arguments  RabbitmqArguments `json:"arguments,omitempty"`
+}
+
+// RabbitmqBindingStatus defines the observed state of RabbitmqBinding
+// +k8s:openapi-gen=true
+type RabbitmqBindingStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// properties key of created binding, used to remove it
+	PropertiesKey string `json:"propertiesKey,omitempty"`
+
+	// vhost, source, destination and type of created binding
+	Vhost           string `json:"vhost,omitempty"`
+	Source          string `json:"source,omitempty"`
+	Destination     string `json:"destination,omitempty"`
+	DestinationType string `json:"destinationType,omitempty"`
+
+	Conditions []RabbitmqCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqBinding is the Schema for the rabbitmqbindings API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Rabbitmq",type="string",JSONPath=".spec.rabbitmq"
+// +kubebuilder:printcolumn:name="Source",type="string",JSONPath=".spec.source"
+// +kubebuilder:printcolumn:name="Destination",type="string",JSONPath=".spec.destination"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+type RabbitmqBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RabbitmqBindingSpec   `json:"spec,omitempty"`
+	Status RabbitmqBindingStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqBindingList contains a list of RabbitmqBinding
+type RabbitmqBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RabbitmqBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RabbitmqBinding{}, &RabbitmqBindingList{})
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RabbitmqExchangeSpec defines the desired state of RabbitmqExchange
+// +k8s:openapi-gen=true
+type RabbitmqExchangeSpec struct {
+	// name of Rabbitmq resource in the same namespace
+	RabbitmqInstance string `json:"rabbitmq"`
+
+	// vhost of exchange, if empty falling to default vhost of Rabbitmq
+	Vhost string `json:"vhost,omitempty"`
+
+	// exchange name in rabbitmq, if empty falling to resource name
+	Name string `json:"name,omitempty"`
+
+	// direct, fanout, topic, headers or plugin type like x-delayed-message, default direct
+	Type string `json:"type,omitempty"`
+
+	// default true
+	Durable    *bool             `json:"durable,omitempty"`
+	AutoDelete bool              `json:"autoDelete,omitempty"`
+	Internal   bool              `json:"internal,omitempty"`
+	Arguments  RabbitmqArguments `json:"arguments,omitempty"`
+}
+
+// RabbitmqExchangeStatus defines the observed state of RabbitmqExchange
+// +k8s:openapi-gen=true
+type RabbitmqExchangeStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	Conditions []RabbitmqCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqExchange is the Schema for the rabbitmqexchanges API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Rabbitmq",type="string",JSONPath=".spec.rabbitmq"
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+type RabbitmqExchange struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RabbitmqExchangeSpec   `json:"spec,omitempty"`
+	Status RabbitmqExchangeStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqExchangeList contains a list of RabbitmqExchange
+type RabbitmqExchangeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RabbitmqExchange `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RabbitmqExchange{}, &RabbitmqExchangeList{})
+}
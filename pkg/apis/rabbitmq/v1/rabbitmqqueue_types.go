@@ -0,0 +1,65 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RabbitmqQueueSpec defines the desired state of RabbitmqQueue
+// +k8s:openapi-gen=true
+type RabbitmqQueueSpec struct {
+	// name of Rabbitmq resource in the same namespace
+	RabbitmqInstance string `json:"rabbitmq"`
+
+	// vhost of queue, if empty falling to default vhost of Rabbitmq
+	Vhost string `json:"vhost,omitempty"`
+
+	// queue name in rabbitmq, if empty falling to resource name
+	Name string `json:"name,omitempty"`
+
+	// classic, quorum or stream, sets x-queue-type argument. Empty uses default queue type of vhost
+	Type string `json:"type,omitempty"`
+
+	// default true, quorum and stream queues are always durable
+	Durable    *bool `json:"durable,omitempty"`
+	AutoDelete bool  `json:"autoDelete,omitempty"`
+
+	// x-dead-letter-exchange, x-max-length, x-message-ttl, x-max-age, etc.
+	Arguments RabbitmqArguments `json:"arguments,omitempty"`
+}
+
+// RabbitmqQueueStatus defines the observed state of RabbitmqQueue
+// +k8s:openapi-gen=true
+type RabbitmqQueueStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	Conditions []RabbitmqCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqQueue is the Schema for the rabbitmqqueues API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Rabbitmq",type="string",JSONPath=".spec.rabbitmq"
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+type RabbitmqQueue struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RabbitmqQueueSpec   `json:"spec,omitempty"`
+	Status RabbitmqQueueStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqQueueList contains a list of RabbitmqQueue
+type RabbitmqQueueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RabbitmqQueue `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RabbitmqQueue{}, &RabbitmqQueueList{})
+}
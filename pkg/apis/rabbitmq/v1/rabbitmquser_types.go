@@ -0,0 +1,85 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RabbitmqConditionReady resource was applied to rabbitmq
+const RabbitmqConditionReady RabbitmqConditionType = "Ready"
+
+// RabbitmqUserPermission configure, write and read regexes for one vhost
+// +k8s:openapi-gen=true
+type RabbitmqUserPermission struct {
+	Vhost     string `json:"vhost"`
+	Configure string `json:"configure"`
+	Write     string `json:"write"`
+	Read      string `json:"read"`
+}
+
+// RabbitmqUserTopicPermission write and read regexes for routing keys of a topic exchange
+// +k8s:openapi-gen=true
+type RabbitmqUserTopicPermission struct {
+	Vhost    string `json:"vhost"`
+	Exchange string `json:"exchange"`
+	Write    string `json:"write"`
+	Read     string `json:"read"`
+}
+
+// RabbitmqUserSpec defines the desired state of RabbitmqUser
+// +k8s:openapi-gen=true
+type RabbitmqUserSpec struct {
+	// name of Rabbitmq resource in the same namespace
+	RabbitmqInstance string `json:"rabbitmq"`
+
+	// username in rabbitmq, if empty falling to resource name
+	Username string `json:"username,omitempty"`
+
+	// secret with generated password, if empty falling to "<resource name>-user-credentials"
+	SecretName string `json:"secretName,omitempty"`
+
+	// user tags: administrator, monitoring, policymaker, management or custom
+	Tags []string `json:"tags,omitempty"`
+
+	Permissions      []RabbitmqUserPermission      `json:"permissions,omitempty"`
+	TopicPermissions []RabbitmqUserTopicPermission `json:"topicPermissions,omitempty"`
+}
+
+// RabbitmqUserStatus defines the observed state of RabbitmqUser
+// +k8s:openapi-gen=true
+type RabbitmqUserStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// secret with username and password
+	SecretName string `json:"secretName,omitempty"`
+
+	Conditions []RabbitmqCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqUser is the Schema for the rabbitmqusers API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Rabbitmq",type="string",JSONPath=".spec.rabbitmq"
+// +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".status.secretName"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+type RabbitmqUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RabbitmqUserSpec   `json:"spec,omitempty"`
+	Status RabbitmqUserStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqUserList contains a list of RabbitmqUser
+type RabbitmqUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RabbitmqUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RabbitmqUser{}, &RabbitmqUserList{})
+}
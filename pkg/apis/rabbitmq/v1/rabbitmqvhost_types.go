@@ -0,0 +1,70 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RabbitmqQueueTypes values supported by default_queue_type of vhost and x-queue-type of queue
+var RabbitmqQueueTypes = []string{"classic", "quorum", "stream"}
+
+// RabbitmqVhostLimits limits of vhost, empty value removes the limit, negative value means unlimited
+// +k8s:openapi-gen=true
+type RabbitmqVhostLimits struct {
+	MaxConnections *int64 `json:"maxConnections,omitempty"`
+	MaxQueues      *int64 `json:"maxQueues,omitempty"`
+}
+
+// RabbitmqVhostSpec defines the desired state of RabbitmqVhost
+// +k8s:openapi-gen=true
+type RabbitmqVhostSpec struct {
+	// name of Rabbitmq resource in the same namespace
+	RabbitmqInstance string `json:"rabbitmq"`
+
+	// vhost name in rabbitmq, if empty falling to resource name
+	Name string `json:"name,omitempty"`
+
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	// queue type used when x-queue-type is not set by client: classic, quorum or stream
+	DefaultQueueType string `json:"defaultQueueType,omitempty"`
+
+	Limits RabbitmqVhostLimits `json:"limits,omitempty"`
+}
+
+// RabbitmqVhostStatus defines the observed state of RabbitmqVhost
+// +k8s:openapi-gen=true
+type RabbitmqVhostStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	Conditions []RabbitmqCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqVhost is the Schema for the rabbitmqvhosts API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Rabbitmq",type="string",JSONPath=".spec.rabbitmq"
+// +kubebuilder:printcolumn:name="Vhost",type="string",JSONPath=".spec.name"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+type RabbitmqVhost struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RabbitmqVhostSpec   `json:"spec,omitempty"`
+	Status RabbitmqVhostStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RabbitmqVhostList contains a list of RabbitmqVhost
+type RabbitmqVhostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RabbitmqVhost `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RabbitmqVhost{}, &RabbitmqVhostList{})
+}
@@ -16,7 +16,7 @@ func (in *Rabbitmq) DeepCopyInto(out *Rabbitmq) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -60,29 +60,41 @@ func (in *RabbitmqAuth) DeepCopy() *RabbitmqAuth {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RabbitmqImage) DeepCopyInto(out *RabbitmqImage) {
+func (in *RabbitmqBinding) DeepCopyInto(out *RabbitmqBinding) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqImage.
-func (in *RabbitmqImage) DeepCopy() *RabbitmqImage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqBinding.
+func (in *RabbitmqBinding) DeepCopy() *RabbitmqBinding {
 	if in == nil {
 		return nil
 	}
-	out := new(RabbitmqImage)
+	out := new(RabbitmqBinding)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RabbitmqList) DeepCopyInto(out *RabbitmqList) {
+func (in *RabbitmqBindingList) DeepCopyInto(out *RabbitmqBindingList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Rabbitmq, len(*in))
+		*out = make([]RabbitmqBinding, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -90,18 +102,18 @@ func (in *RabbitmqList) DeepCopyInto(out *RabbitmqList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqList.
-func (in *RabbitmqList) DeepCopy() *RabbitmqList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqBindingList.
+func (in *RabbitmqBindingList) DeepCopy() *RabbitmqBindingList {
 	if in == nil {
 		return nil
 	}
-	out := new(RabbitmqList)
+	out := new(RabbitmqBindingList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RabbitmqList) DeepCopyObject() runtime.Object {
+func (in *RabbitmqBindingList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -109,115 +121,266 @@ func (in *RabbitmqList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RabbitmqPolicy) DeepCopyInto(out *RabbitmqPolicy) {
+func (in *RabbitmqBindingSpec) DeepCopyInto(out *RabbitmqBindingSpec) {
 	*out = *in
-	out.Definition = in.Definition
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		(*in).DeepCopyInto(out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqPolicy.
-func (in *RabbitmqPolicy) DeepCopy() *RabbitmqPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqBindingSpec.
+func (in *RabbitmqBindingSpec) DeepCopy() *RabbitmqBindingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RabbitmqPolicy)
+	out := new(RabbitmqBindingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RabbitmqPolicyDefinition) DeepCopyInto(out *RabbitmqPolicyDefinition) {
+func (in *RabbitmqBindingStatus) DeepCopyInto(out *RabbitmqBindingStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RabbitmqCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqPolicyDefinition.
-func (in *RabbitmqPolicyDefinition) DeepCopy() *RabbitmqPolicyDefinition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqBindingStatus.
+func (in *RabbitmqBindingStatus) DeepCopy() *RabbitmqBindingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RabbitmqPolicyDefinition)
+	out := new(RabbitmqBindingStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RabbitmqSSL) DeepCopyInto(out *RabbitmqSSL) {
+func (in *RabbitmqCertificateStatus) DeepCopyInto(out *RabbitmqCertificateStatus) {
+	*out = *in
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.RenewalTime != nil {
+		in, out := &in.RenewalTime, &out.RenewalTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqCertificateStatus.
+func (in *RabbitmqCertificateStatus) DeepCopy() *RabbitmqCertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqCertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqCondition) DeepCopyInto(out *RabbitmqCondition) {
 	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSSL.
-func (in *RabbitmqSSL) DeepCopy() *RabbitmqSSL {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqCondition.
+func (in *RabbitmqCondition) DeepCopy() *RabbitmqCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(RabbitmqSSL)
+	out := new(RabbitmqCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RabbitmqSpec) DeepCopyInto(out *RabbitmqSpec) {
+func (in *RabbitmqCredentialsStatus) DeepCopyInto(out *RabbitmqCredentialsStatus) {
 	*out = *in
-	in.RabbitmqPdb.DeepCopyInto(&out.RabbitmqPdb)
-	out.RabbitmqSSL = in.RabbitmqSSL
-	in.RabbitmqAuth.DeepCopyInto(&out.RabbitmqAuth)
-	if in.RabbitmqPolicies != nil {
-		in, out := &in.RabbitmqPolicies, &out.RabbitmqPolicies
-		*out = make([]RabbitmqPolicy, len(*in))
-		copy(*out, *in)
+	if in.LastPasswordRotation != nil {
+		in, out := &in.LastPasswordRotation, &out.LastPasswordRotation
+		*out = (*in).DeepCopy()
 	}
-	if in.RabbitmqPlugins != nil {
-		in, out := &in.RabbitmqPlugins, &out.RabbitmqPlugins
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.LastCookieRotation != nil {
+		in, out := &in.LastCookieRotation, &out.LastCookieRotation
+		*out = (*in).DeepCopy()
 	}
-	if in.K8SENV != nil {
-		in, out := &in.K8SENV, &out.K8SENV
-		*out = make([]corev1.EnvVar, len(*in))
+	if in.LastClusterRestart != nil {
+		in, out := &in.LastClusterRestart, &out.LastClusterRestart
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqCredentialsStatus.
+func (in *RabbitmqCredentialsStatus) DeepCopy() *RabbitmqCredentialsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqCredentialsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqExchange) DeepCopyInto(out *RabbitmqExchange) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqExchange.
+func (in *RabbitmqExchange) DeepCopy() *RabbitmqExchange {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqExchange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqExchange) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqExchangeList) DeepCopyInto(out *RabbitmqExchangeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RabbitmqExchange, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.K8SImage = in.K8SImage
-	if in.K8SLabels != nil {
-		in, out := &in.K8SLabels, &out.K8SLabels
-		*out = make([]metav1.LabelSelector, len(*in))
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqExchangeList.
+func (in *RabbitmqExchangeList) DeepCopy() *RabbitmqExchangeList {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqExchangeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqExchangeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqExchangeSpec) DeepCopyInto(out *RabbitmqExchangeSpec) {
+	*out = *in
+	if in.Durable != nil {
+		in, out := &in.Durable, &out.Durable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		(*in).DeepCopyInto(out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqExchangeSpec.
+func (in *RabbitmqExchangeSpec) DeepCopy() *RabbitmqExchangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqExchangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqExchangeStatus) DeepCopyInto(out *RabbitmqExchangeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RabbitmqCondition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.RabbitmqVolumeSize = in.RabbitmqVolumeSize.DeepCopy()
-	if in.RabbitmqPodRequests != nil {
-		in, out := &in.RabbitmqPodRequests, &out.RabbitmqPodRequests
-		*out = make(corev1.ResourceList, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val.DeepCopy()
-		}
-	}
-	if in.RabbitmqPodLimits != nil {
-		in, out := &in.RabbitmqPodLimits, &out.RabbitmqPodLimits
-		*out = make(corev1.ResourceList, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val.DeepCopy()
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqExchangeStatus.
+func (in *RabbitmqExchangeStatus) DeepCopy() *RabbitmqExchangeStatus {
+	if in == nil {
+		return nil
 	}
-	if in.RabbitmqAffinity != nil {
-		in, out := &in.RabbitmqAffinity, &out.RabbitmqAffinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
+	out := new(RabbitmqExchangeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqImage) DeepCopyInto(out *RabbitmqImage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqImage.
+func (in *RabbitmqImage) DeepCopy() *RabbitmqImage {
+	if in == nil {
+		return nil
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out := new(RabbitmqImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqIssuerRef) DeepCopyInto(out *RabbitmqIssuerRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqIssuerRef.
+func (in *RabbitmqIssuerRef) DeepCopy() *RabbitmqIssuerRef {
+	if in == nil {
+		return nil
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
+	out := new(RabbitmqIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqList) DeepCopyInto(out *RabbitmqList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Rabbitmq, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -225,28 +388,717 @@ func (in *RabbitmqSpec) DeepCopyInto(out *RabbitmqSpec) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSpec.
-func (in *RabbitmqSpec) DeepCopy() *RabbitmqSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqList.
+func (in *RabbitmqList) DeepCopy() *RabbitmqList {
 	if in == nil {
 		return nil
 	}
-	out := new(RabbitmqSpec)
+	out := new(RabbitmqList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RabbitmqStatus) DeepCopyInto(out *RabbitmqStatus) {
+func (in *RabbitmqNodeStatus) DeepCopyInto(out *RabbitmqNodeStatus) {
+	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqNodeStatus.
+func (in *RabbitmqNodeStatus) DeepCopy() *RabbitmqNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqPolicy) DeepCopyInto(out *RabbitmqPolicy) {
 	*out = *in
+	if in.Definition != nil {
+		in, out := &in.Definition, &out.Definition
+		(*in).DeepCopyInto(out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqStatus.
-func (in *RabbitmqStatus) DeepCopy() *RabbitmqStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqPolicy.
+func (in *RabbitmqPolicy) DeepCopy() *RabbitmqPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(RabbitmqStatus)
+	out := new(RabbitmqPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqPolicyReference) DeepCopyInto(out *RabbitmqPolicyReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqPolicyReference.
+func (in *RabbitmqPolicyReference) DeepCopy() *RabbitmqPolicyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqPolicyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqQueue) DeepCopyInto(out *RabbitmqQueue) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqQueue.
+func (in *RabbitmqQueue) DeepCopy() *RabbitmqQueue {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqQueue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqQueue) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqQueueList) DeepCopyInto(out *RabbitmqQueueList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RabbitmqQueue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqQueueList.
+func (in *RabbitmqQueueList) DeepCopy() *RabbitmqQueueList {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqQueueList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqQueueList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqQueueSpec) DeepCopyInto(out *RabbitmqQueueSpec) {
+	*out = *in
+	if in.Durable != nil {
+		in, out := &in.Durable, &out.Durable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		(*in).DeepCopyInto(out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqQueueSpec.
+func (in *RabbitmqQueueSpec) DeepCopy() *RabbitmqQueueSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqQueueSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqQueueStatus) DeepCopyInto(out *RabbitmqQueueStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RabbitmqCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqQueueStatus.
+func (in *RabbitmqQueueStatus) DeepCopy() *RabbitmqQueueStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqQueueStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqRolloutStatus) DeepCopyInto(out *RabbitmqRolloutStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqRolloutStatus.
+func (in *RabbitmqRolloutStatus) DeepCopy() *RabbitmqRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqSSL) DeepCopyInto(out *RabbitmqSSL) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(RabbitmqIssuerRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSSL.
+func (in *RabbitmqSSL) DeepCopy() *RabbitmqSSL {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqSSL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqScaleDownStatus) DeepCopyInto(out *RabbitmqScaleDownStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqScaleDownStatus.
+func (in *RabbitmqScaleDownStatus) DeepCopy() *RabbitmqScaleDownStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqScaleDownStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqSpec) DeepCopyInto(out *RabbitmqSpec) {
+	*out = *in
+	in.RabbitmqPdb.DeepCopyInto(&out.RabbitmqPdb)
+	in.RabbitmqSSL.DeepCopyInto(&out.RabbitmqSSL)
+	in.RabbitmqAuth.DeepCopyInto(&out.RabbitmqAuth)
+	if in.RabbitmqPolicies != nil {
+		in, out := &in.RabbitmqPolicies, &out.RabbitmqPolicies
+		*out = make([]RabbitmqPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RabbitmqOperatorPolicies != nil {
+		in, out := &in.RabbitmqOperatorPolicies, &out.RabbitmqOperatorPolicies
+		*out = make([]RabbitmqPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RabbitmqPlugins != nil {
+		in, out := &in.RabbitmqPlugins, &out.RabbitmqPlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.K8SENV != nil {
+		in, out := &in.K8SENV, &out.K8SENV
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.K8SImage = in.K8SImage
+	if in.K8SLabels != nil {
+		in, out := &in.K8SLabels, &out.K8SLabels
+		*out = make([]metav1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.RabbitmqVolumeSize = in.RabbitmqVolumeSize.DeepCopy()
+	if in.RabbitmqPodRequests != nil {
+		in, out := &in.RabbitmqPodRequests, &out.RabbitmqPodRequests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.RabbitmqPodLimits != nil {
+		in, out := &in.RabbitmqPodLimits, &out.RabbitmqPodLimits
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.RabbitmqAffinity != nil {
+		in, out := &in.RabbitmqAffinity, &out.RabbitmqAffinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RabbitmqReadinessProbe != nil {
+		in, out := &in.RabbitmqReadinessProbe, &out.RabbitmqReadinessProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RabbitmqLivenessProbe != nil {
+		in, out := &in.RabbitmqLivenessProbe, &out.RabbitmqLivenessProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RabbitmqTerminationGracePeriodSeconds != nil {
+		in, out := &in.RabbitmqTerminationGracePeriodSeconds, &out.RabbitmqTerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RabbitmqPasswordRotationPeriod != nil {
+		in, out := &in.RabbitmqPasswordRotationPeriod, &out.RabbitmqPasswordRotationPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RabbitmqBindingNamespaces != nil {
+		in, out := &in.RabbitmqBindingNamespaces, &out.RabbitmqBindingNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqSpec.
+func (in *RabbitmqSpec) DeepCopy() *RabbitmqSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqStatus) DeepCopyInto(out *RabbitmqStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]RabbitmqNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]RabbitmqPolicyReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.OperatorPolicies != nil {
+		in, out := &in.OperatorPolicies, &out.OperatorPolicies
+		*out = make([]RabbitmqPolicyReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RabbitmqRolloutStatus)
+		**out = **in
+	}
+	if in.ScaleDown != nil {
+		in, out := &in.ScaleDown, &out.ScaleDown
+		*out = new(RabbitmqScaleDownStatus)
+		**out = **in
+	}
+	if in.VolumeExpansion != nil {
+		in, out := &in.VolumeExpansion, &out.VolumeExpansion
+		*out = new(RabbitmqVolumeExpansionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(RabbitmqCertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(RabbitmqCredentialsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BindingNamespaces != nil {
+		in, out := &in.BindingNamespaces, &out.BindingNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RabbitmqCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqStatus.
+func (in *RabbitmqStatus) DeepCopy() *RabbitmqStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqUser) DeepCopyInto(out *RabbitmqUser) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqUser.
+func (in *RabbitmqUser) DeepCopy() *RabbitmqUser {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqUserList) DeepCopyInto(out *RabbitmqUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RabbitmqUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqUserList.
+func (in *RabbitmqUserList) DeepCopy() *RabbitmqUserList {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqUserPermission) DeepCopyInto(out *RabbitmqUserPermission) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqUserPermission.
+func (in *RabbitmqUserPermission) DeepCopy() *RabbitmqUserPermission {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqUserPermission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqUserSpec) DeepCopyInto(out *RabbitmqUserSpec) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]RabbitmqUserPermission, len(*in))
+		copy(*out, *in)
+	}
+	if in.TopicPermissions != nil {
+		in, out := &in.TopicPermissions, &out.TopicPermissions
+		*out = make([]RabbitmqUserTopicPermission, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqUserSpec.
+func (in *RabbitmqUserSpec) DeepCopy() *RabbitmqUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqUserStatus) DeepCopyInto(out *RabbitmqUserStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RabbitmqCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqUserStatus.
+func (in *RabbitmqUserStatus) DeepCopy() *RabbitmqUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqUserTopicPermission) DeepCopyInto(out *RabbitmqUserTopicPermission) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqUserTopicPermission.
+func (in *RabbitmqUserTopicPermission) DeepCopy() *RabbitmqUserTopicPermission {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqUserTopicPermission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqVhost) DeepCopyInto(out *RabbitmqVhost) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqVhost.
+func (in *RabbitmqVhost) DeepCopy() *RabbitmqVhost {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqVhost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqVhost) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqVhostLimits) DeepCopyInto(out *RabbitmqVhostLimits) {
+	*out = *in
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxQueues != nil {
+		in, out := &in.MaxQueues, &out.MaxQueues
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqVhostLimits.
+func (in *RabbitmqVhostLimits) DeepCopy() *RabbitmqVhostLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqVhostLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqVhostList) DeepCopyInto(out *RabbitmqVhostList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RabbitmqVhost, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqVhostList.
+func (in *RabbitmqVhostList) DeepCopy() *RabbitmqVhostList {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqVhostList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RabbitmqVhostList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqVhostSpec) DeepCopyInto(out *RabbitmqVhostSpec) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Limits.DeepCopyInto(&out.Limits)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqVhostSpec.
+func (in *RabbitmqVhostSpec) DeepCopy() *RabbitmqVhostSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqVhostSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqVhostStatus) DeepCopyInto(out *RabbitmqVhostStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RabbitmqCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqVhostStatus.
+func (in *RabbitmqVhostStatus) DeepCopy() *RabbitmqVhostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqVhostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqVolumeExpansionStatus) DeepCopyInto(out *RabbitmqVolumeExpansionStatus) {
+	*out = *in
+	in.Size.DeepCopyInto(&out.Size)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqVolumeExpansionStatus.
+func (in *RabbitmqVolumeExpansionStatus) DeepCopy() *RabbitmqVolumeExpansionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqVolumeExpansionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -13,9 +13,40 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.Rabbitmq":       schema_pkg_apis_rabbitmq_v1_Rabbitmq(ref),
-		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqSpec":   schema_pkg_apis_rabbitmq_v1_RabbitmqSpec(ref),
-		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqStatus": schema_pkg_apis_rabbitmq_v1_RabbitmqStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.Rabbitmq":                      schema_pkg_apis_rabbitmq_v1_Rabbitmq(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqAuth":                  schema_pkg_apis_rabbitmq_v1_RabbitmqAuth(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqBinding":               schema_pkg_apis_rabbitmq_v1_RabbitmqBinding(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqBindingSpec":           schema_pkg_apis_rabbitmq_v1_RabbitmqBindingSpec(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqBindingStatus":         schema_pkg_apis_rabbitmq_v1_RabbitmqBindingStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCertificateStatus":     schema_pkg_apis_rabbitmq_v1_RabbitmqCertificateStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition":             schema_pkg_apis_rabbitmq_v1_RabbitmqCondition(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCredentialsStatus":     schema_pkg_apis_rabbitmq_v1_RabbitmqCredentialsStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqExchange":              schema_pkg_apis_rabbitmq_v1_RabbitmqExchange(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqExchangeSpec":          schema_pkg_apis_rabbitmq_v1_RabbitmqExchangeSpec(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqExchangeStatus":        schema_pkg_apis_rabbitmq_v1_RabbitmqExchangeStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqImage":                 schema_pkg_apis_rabbitmq_v1_RabbitmqImage(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqIssuerRef":             schema_pkg_apis_rabbitmq_v1_RabbitmqIssuerRef(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqNodeStatus":            schema_pkg_apis_rabbitmq_v1_RabbitmqNodeStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqPolicy":                schema_pkg_apis_rabbitmq_v1_RabbitmqPolicy(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqPolicyReference":       schema_pkg_apis_rabbitmq_v1_RabbitmqPolicyReference(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqQueue":                 schema_pkg_apis_rabbitmq_v1_RabbitmqQueue(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqQueueSpec":             schema_pkg_apis_rabbitmq_v1_RabbitmqQueueSpec(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqQueueStatus":           schema_pkg_apis_rabbitmq_v1_RabbitmqQueueStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqRolloutStatus":         schema_pkg_apis_rabbitmq_v1_RabbitmqRolloutStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqSSL":                   schema_pkg_apis_rabbitmq_v1_RabbitmqSSL(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqScaleDownStatus":       schema_pkg_apis_rabbitmq_v1_RabbitmqScaleDownStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqSpec":                  schema_pkg_apis_rabbitmq_v1_RabbitmqSpec(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqStatus":                schema_pkg_apis_rabbitmq_v1_RabbitmqStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUser":                  schema_pkg_apis_rabbitmq_v1_RabbitmqUser(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserPermission":        schema_pkg_apis_rabbitmq_v1_RabbitmqUserPermission(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserSpec":              schema_pkg_apis_rabbitmq_v1_RabbitmqUserSpec(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserStatus":            schema_pkg_apis_rabbitmq_v1_RabbitmqUserStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserTopicPermission":   schema_pkg_apis_rabbitmq_v1_RabbitmqUserTopicPermission(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhost":                 schema_pkg_apis_rabbitmq_v1_RabbitmqVhost(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostLimits":           schema_pkg_apis_rabbitmq_v1_RabbitmqVhostLimits(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostSpec":             schema_pkg_apis_rabbitmq_v1_RabbitmqVhostSpec(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostStatus":           schema_pkg_apis_rabbitmq_v1_RabbitmqVhostStatus(ref),
+		"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVolumeExpansionStatus": schema_pkg_apis_rabbitmq_v1_RabbitmqVolumeExpansionStatus(ref),
 	}
 }
 
@@ -62,26 +93,1938 @@ func schema_pkg_apis_rabbitmq_v1_Rabbitmq(ref common.ReferenceCallback) common.O
 	}
 }
 
+func schema_pkg_apis_rabbitmq_v1_RabbitmqAuth(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqAuth auth config",
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"mechanisms": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"enabled"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqBinding(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqBinding is the Schema for the rabbitmqbindings API",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqBindingSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqBindingStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqBindingSpec", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqBindingStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqBindingSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqBindingSpec defines the desired state of RabbitmqBinding",
+				Properties: map[string]spec.Schema{
+					"rabbitmq": {
+						SchemaProps: spec.SchemaProps{
+							Description: "name of Rabbitmq resource in the same namespace",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"vhost": {
+						SchemaProps: spec.SchemaProps{
+							Description: "vhost of binding, if empty falling to default vhost of Rabbitmq",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "source exchange name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"destination": {
+						SchemaProps: spec.SchemaProps{
+							Description: "destination queue or exchange name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"destinationType": {
+						SchemaProps: spec.SchemaProps{
+							Description: "queue or exchange, default queue",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"routingKey": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"arguments": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"object"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"rabbitmq", "source", "destination"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqBindingStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqBindingStatus defines the observed state of RabbitmqBinding",
+				Properties: map[string]spec.Schema{
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"propertiesKey": {
+						SchemaProps: spec.SchemaProps{
+							Description: "properties key of created binding, used to remove it",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"vhost": {
+						SchemaProps: spec.SchemaProps{
+							Description: "vhost, source, destination and type of created binding",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"destination": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"destinationType": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqCertificateStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqCertificateStatus server certificate issued by cert-manager",
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Certificate and its secret",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"notAfter": {
+						SchemaProps: spec.SchemaProps{
+							Description: "expiration of the issued certificate",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"renewalTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "time cert-manager renews the certificate at",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"name", "secretName"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqCondition describes the state of one aspect of the cluster at a certain point",
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"lastTransitionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "last time the condition changed its status",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "machine readable reason of the last transition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "human readable details",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqCredentialsStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqCredentialsStatus rotation of service account password and erlang cookie",
+				Properties: map[string]spec.Schema{
+					"lastPasswordRotation": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastCookieRotation": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastClusterRestart": {
+						SchemaProps: spec.SchemaProps{
+							Description: "nodes were stopped by the last full restart, pods created before are restarted",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"passwordRotationRequest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "values of rotate annotations handled last",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cookieRotationRequest": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"passwordRotationPhase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "step of rotation in progress, empty when nothing is rotated. Cookie phases are also used by a full restart switching interNodeTLS",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cookieRotationPhase": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "what rotation is waiting for",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqExchange(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqExchange is the Schema for the rabbitmqexchanges API",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqExchangeSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqExchangeStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqExchangeSpec", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqExchangeStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqExchangeSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqExchangeSpec defines the desired state of RabbitmqExchange",
+				Properties: map[string]spec.Schema{
+					"rabbitmq": {
+						SchemaProps: spec.SchemaProps{
+							Description: "name of Rabbitmq resource in the same namespace",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"vhost": {
+						SchemaProps: spec.SchemaProps{
+							Description: "vhost of exchange, if empty falling to default vhost of Rabbitmq",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "exchange name in rabbitmq, if empty falling to resource name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "direct, fanout, topic, headers or plugin type like x-delayed-message, default direct",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"durable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "default true",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"autoDelete": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"internal": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"arguments": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"object"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"rabbitmq"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqExchangeStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqExchangeStatus defines the observed state of RabbitmqExchange",
+				Properties: map[string]spec.Schema{
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqImage(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqImage Sets image url and tag",
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"tag": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"name", "tag"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqIssuerRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqIssuerRef cert-manager Issuer or ClusterIssuer of server certificate",
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Issuer by default",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"group": {
+						SchemaProps: spec.SchemaProps{
+							Description: "cert-manager.io by default, set for external issuers",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqNodeStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqNodeStatus node state as reported by /api/nodes",
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"running": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "disc or ram",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"partitions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "nodes this node can't reach, not empty in case of network partition",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"name", "running"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqPolicy type",
+				Properties: map[string]spec.Schema{
+					"vhost": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"pattern": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"definition": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"object"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"priority": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"apply-to": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"adopt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "take over policy with the same vhost and name created outside of operator",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "pattern", "definition", "priority", "apply-to"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqPolicyReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqPolicyReference vhost and name of policy created by operator",
+				Properties: map[string]spec.Schema{
+					"vhost": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"vhost", "name"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqQueue(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqQueue is the Schema for the rabbitmqqueues API",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqQueueSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqQueueStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqQueueSpec", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqQueueStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqQueueSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqQueueSpec defines the desired state of RabbitmqQueue",
+				Properties: map[string]spec.Schema{
+					"rabbitmq": {
+						SchemaProps: spec.SchemaProps{
+							Description: "name of Rabbitmq resource in the same namespace",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"vhost": {
+						SchemaProps: spec.SchemaProps{
+							Description: "vhost of queue, if empty falling to default vhost of Rabbitmq",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "queue name in rabbitmq, if empty falling to resource name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "classic, quorum or stream, sets x-queue-type argument. Empty uses default queue type of vhost",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"durable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "default true, quorum and stream queues are always durable",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"autoDelete": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"arguments": {
+						SchemaProps: spec.SchemaProps{
+							Description: "x-dead-letter-exchange, x-max-length, x-message-ttl, x-max-age, etc.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"object"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"rabbitmq"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqQueueStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqQueueStatus defines the observed state of RabbitmqQueue",
+				Properties: map[string]spec.Schema{
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqRolloutStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqRolloutStatus progress of pod by pod restart, next pod is restarted when the cluster is healthy again",
+				Properties: map[string]spec.Schema{
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "statefulset revision pods are restarted to",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"updatedReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "pods running the revision",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"restartingPod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "pod deleted last",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "what rollout is waiting for",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"revision", "updatedReplicas"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqSSL(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqSSL sets SSL parameters",
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"exitingSecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "secret with CA, certificate and key, it is mounted into rabbitmq container",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cacertfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "keys of the secret, ca.crt, tls.crt and tls.key by default",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"certfile": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"keyfile": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"verify": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ssl_options.verify, verify_none or verify_peer",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"failIfNoPeerCert": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ssl_options.fail_if_no_peer_cert, requires verify_peer",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"disablePlaintext": {
+						SchemaProps: spec.SchemaProps{
+							Description: "disables plaintext AMQP listener on 5672",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"interNodeTLS": {
+						SchemaProps: spec.SchemaProps{
+							Description: "erlang distribution between nodes and CLI tools over TLS with the same certificate, requires verify of peers",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"issuerRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "cert-manager issuer, operator creates Certificate which writes exitingSecret",
+							Ref:         ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqIssuerRef"),
+						},
+					},
+				},
+				Required: []string{"enabled"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqIssuerRef"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqScaleDownStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqScaleDownStatus progress of removing the highest ordinal node, statefulset replicas are reduced after the node left cluster",
+				Properties: map[string]spec.Schema{
+					"pod": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"node": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "the step to run next",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "error of the last attempt",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"pod", "node", "phase"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_rabbitmq_v1_RabbitmqSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "RabbitmqSpec defines the desired state of Rabbitmq",
-				Properties:  map[string]spec.Schema{},
+				Description: "RabbitmqSpec defines the desired state of Rabbitmq",
+				Properties: map[string]spec.Schema{
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"pdb": {
+						SchemaProps: spec.SchemaProps{
+							Description: "set PodDisruptionBudget. Default values: if replicas >= 2, MaxUnavailable = 1 else if replicas = 1, maxU = 1",
+							Ref:         ref("k8s.io/api/policy/v1beta1.PodDisruptionBudget"),
+						},
+					},
+					"default_vhost": {
+						SchemaProps: spec.SchemaProps{
+							Description: "set default_vhost, if empty falling to \"rabbit\"; policies without vhost use it only when it is set, \"/\" otherwise",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secret_credentials": {
+						SchemaProps: spec.SchemaProps{
+							Description: "all secrets generated once with CRDs name, but you can set it by hands usualy not needed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secret_service_account": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"memory_high_watermark": {
+						SchemaProps: spec.SchemaProps{
+							Description: "working now, but will be ignored in future versions",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"vm_memory_high_watermark_paging_ratio": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"memory_high_watermark_relative": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"hipe_compile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Hipe",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"cert": {
+						SchemaProps: spec.SchemaProps{
+							Description: "set SSL settings, AMQPS listens on 5671 and management HTTPS on 15671",
+							Ref:         ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqSSL"),
+						},
+					},
+					"auth": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqAuth"),
+						},
+					},
+					"rabbitmq_storage_class": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"policies": {
+						SchemaProps: spec.SchemaProps{
+							Description: "set rabbitmq policies",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqPolicy"),
+									},
+								},
+							},
+						},
+					},
+					"operatorPolicies": {
+						SchemaProps: spec.SchemaProps{
+							Description: "operator policies cap queue arguments, values can't be overridden by policies or by clients. Only RabbitmqOperatorPolicyKeys are allowed in definition",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqPolicy"),
+									},
+								},
+							},
+						},
+					},
+					"plugins": {
+						SchemaProps: spec.SchemaProps{
+							Description: "load additional plugins",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"k8s_serviceaccount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "serviceaccount",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"k8s_service_discovery": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "set your own ENV variables in k8s style",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "you can set your own image instead of official",
+							Ref:         ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqImage"),
+						},
+					},
+					"k8s_labels": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+									},
+								},
+							},
+						},
+					},
+					"volume_size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PersistentVolumeClaim in k8s style",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"pod_requests": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"pod_limits": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"k8s_host": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"k8s_addrtype": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"k8s_peer_discovery_backend": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"cluster_node_cleanup_interval": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"cluster_partition_handling": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"prometheus_exporter_port": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"prometheus_image": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"affinity": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/api/core/v1.Affinity"),
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"tolerations": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+					"use_service_monitor": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"configUpdatePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "how changes of rabbitmq.conf and enabled_plugins reach running pods: RollingRestart restarts pods one by one, OnNextRestart keeps pods running until they are restarted for other reasons",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"deletionPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "what happens to data PVCs when Rabbitmq is deleted: Retain keeps them, Delete removes them, SnapshotThenDelete removes them after VolumeSnapshots of every PVC are ready",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"volumeSnapshotClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VolumeSnapshotClass of snapshots taken by SnapshotThenDelete, default class of the CSI driver if empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"readinessProbe": {
+						SchemaProps: spec.SchemaProps{
+							Description: "probes of rabbitmq container, defaults are TCP check of AMQP port for readiness and rabbitmq-diagnostics ping for liveness with initial delay growing with volume_size",
+							Ref:         ref("k8s.io/api/core/v1.Probe"),
+						},
+					},
+					"livenessProbe": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/api/core/v1.Probe"),
+						},
+					},
+					"terminationGracePeriodSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "time for preStop hook to wait for quorum queue replicas and drain the node, then rabbitmq is killed",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"rotationPeriod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "service account password is rotated when it is older, never by default",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"bindingNamespaces": {
+						SchemaProps: spec.SchemaProps{
+							Description: "binding secrets of the instance and its users are copied to these namespaces",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"replicas", "policies", "plugins", "k8s_serviceaccount", "k8s_service_discovery", "image", "k8s_labels", "volume_size", "k8s_host", "k8s_addrtype", "k8s_peer_discovery_backend", "cluster_node_cleanup_interval", "cluster_partition_handling", "nodeSelector", "tolerations"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqAuth", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqImage", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqPolicy", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqSSL", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.Probe", "k8s.io/api/core/v1.Toleration", "k8s.io/api/policy/v1beta1.PodDisruptionBudget", "k8s.io/apimachinery/pkg/api/resource.Quantity", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqStatus defines the observed state of Rabbitmq",
+				Properties: map[string]spec.Schema{
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "generation of the spec the status was calculated for",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"readyReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ready pods of the statefulset",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rabbitmqVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "running versions, taken from /api/overview",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"erlangVersion": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"nodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "cluster members, taken from /api/nodes",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqNodeStatus"),
+									},
+								},
+							},
+						},
+					},
+					"policies": {
+						SchemaProps: spec.SchemaProps{
+							Description: "policies owned by operator, only these are removed when missing in spec",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqPolicyReference"),
+									},
+								},
+							},
+						},
+					},
+					"operatorPolicies": {
+						SchemaProps: spec.SchemaProps{
+							Description: "operator policies owned by operator",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqPolicyReference"),
+									},
+								},
+							},
+						},
+					},
+					"policiesTracked": {
+						SchemaProps: spec.SchemaProps{
+							Description: "policies are recorded as owned, false for clusters managed by operator versions which did not record them, their policies listed in spec are taken over on first sync",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"rollout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "restart of pods with new statefulset revision, empty when all pods are up to date",
+							Ref:         ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqRolloutStatus"),
+						},
+					},
+					"scaleDown": {
+						SchemaProps: spec.SchemaProps{
+							Description: "removal of node on scale down, empty when no node is being removed",
+							Ref:         ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqScaleDownStatus"),
+						},
+					},
+					"volumeExpansion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "resize of data volumes, empty when all volumes have requested size",
+							Ref:         ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVolumeExpansionStatus"),
+						},
+					},
+					"certificate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "server certificate issued by cert-manager, empty without cert.issuerRef",
+							Ref:         ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCertificateStatus"),
+						},
+					},
+					"credentials": {
+						SchemaProps: spec.SchemaProps{
+							Description: "rotation of service account password and erlang cookie",
+							Ref:         ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCredentialsStatus"),
+						},
+					},
+					"queueMembershipReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "replicas quorum queue membership was grown to, new nodes become members of existing quorum queues on scale up",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"bindingNamespaces": {
+						SchemaProps: spec.SchemaProps{
+							Description: "namespaces with copies of binding secrets, only these are searched for copies to update or delete",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"readyReplicas"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCertificateStatus", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCredentialsStatus", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqNodeStatus", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqPolicyReference", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqRolloutStatus", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqScaleDownStatus", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVolumeExpansionStatus"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqUser(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqUser is the Schema for the rabbitmqusers API",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserSpec", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqUserPermission(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqUserPermission configure, write and read regexes for one vhost",
+				Properties: map[string]spec.Schema{
+					"vhost": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"configure": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"write": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"read": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"vhost", "configure", "write", "read"},
 			},
 		},
 		Dependencies: []string{},
 	}
 }
 
-func schema_pkg_apis_rabbitmq_v1_RabbitmqStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_rabbitmq_v1_RabbitmqUserSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "RabbitmqStatus defines the observed state of Rabbitmq",
-				Properties:  map[string]spec.Schema{},
+				Description: "RabbitmqUserSpec defines the desired state of RabbitmqUser",
+				Properties: map[string]spec.Schema{
+					"rabbitmq": {
+						SchemaProps: spec.SchemaProps{
+							Description: "name of Rabbitmq resource in the same namespace",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"username": {
+						SchemaProps: spec.SchemaProps{
+							Description: "username in rabbitmq, if empty falling to resource name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "secret with generated password, if empty falling to \"<resource name>-user-credentials\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tags": {
+						SchemaProps: spec.SchemaProps{
+							Description: "user tags: administrator, monitoring, policymaker, management or custom",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"permissions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserPermission"),
+									},
+								},
+							},
+						},
+					},
+					"topicPermissions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserTopicPermission"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"rabbitmq"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserPermission", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqUserTopicPermission"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqUserStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqUserStatus defines the observed state of RabbitmqUser",
+				Properties: map[string]spec.Schema{
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "secret with username and password",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqUserTopicPermission(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqUserTopicPermission write and read regexes for routing keys of a topic exchange",
+				Properties: map[string]spec.Schema{
+					"vhost": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"exchange": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"write": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"read": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"vhost", "exchange", "write", "read"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqVhost(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqVhost is the Schema for the rabbitmqvhosts API",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostSpec", "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqVhostLimits(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqVhostLimits limits of vhost, empty value removes the limit, negative value means unlimited",
+				Properties: map[string]spec.Schema{
+					"maxConnections": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"maxQueues": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+				},
 			},
 		},
 		Dependencies: []string{},
 	}
 }
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqVhostSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqVhostSpec defines the desired state of RabbitmqVhost",
+				Properties: map[string]spec.Schema{
+					"rabbitmq": {
+						SchemaProps: spec.SchemaProps{
+							Description: "name of Rabbitmq resource in the same namespace",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "vhost name in rabbitmq, if empty falling to resource name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"description": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"tags": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"defaultQueueType": {
+						SchemaProps: spec.SchemaProps{
+							Description: "queue type used when x-queue-type is not set by client: classic, quorum or stream",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostLimits"),
+						},
+					},
+				},
+				Required: []string{"rabbitmq"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqVhostLimits"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqVhostStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqVhostStatus defines the observed state of RabbitmqVhost",
+				Properties: map[string]spec.Schema{
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1.RabbitmqCondition"},
+	}
+}
+
+func schema_pkg_apis_rabbitmq_v1_RabbitmqVolumeExpansionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RabbitmqVolumeExpansionStatus progress of data volumes resize after volume_size was increased",
+				Properties: map[string]spec.Schema{
+					"size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "requested size of volumes",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"expandedVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "volumes which have requested capacity",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"volumes": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "what expansion is waiting for",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"size", "expandedVolumes", "volumes"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
@@ -6,5 +6,5 @@ import (
 
 func init() {
 	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
-	AddToManagerFuncs = append(AddToManagerFuncs, rabbitmq.Add)
+	AddToManagerFuncs = append(AddToManagerFuncs, rabbitmq.Add, rabbitmq.AddRabbitmqUser, rabbitmq.AddRabbitmqVhost, rabbitmq.AddRabbitmqExchange, rabbitmq.AddRabbitmqQueue, rabbitmq.AddRabbitmqBinding)
 }
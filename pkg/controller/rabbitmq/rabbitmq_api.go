@@ -1,10 +1,12 @@
 package rabbitmq
 
 import (
-	"encoding/json"
+	"net"
 
 	"github.com/go-logr/logr"
 	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func (r *ReconcileRabbitmq) apiServiceAddress(cr *rabbitmqv1.Rabbitmq) string {
@@ -17,103 +19,42 @@ func (r *ReconcileRabbitmq) apiServiceHostname(cr *rabbitmqv1.Rabbitmq) string {
 	return apiHostname
 }
 
-// Policies block
-
-func (r *ReconcileRabbitmq) apiPolicyList(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret basicAuthCredentials) ([]rabbitmqv1.RabbitmqPolicy, error) {
-	url := r.apiServiceAddress(cr) + "/api/policies"
-
-	response, err := getRequest(url, secret)
-	if err != nil {
-		reqLogger.Info("Error while receiving policies list", "Error", err)
-		return nil, err
-	}
-
-	var policies []rabbitmqv1.RabbitmqPolicy
-	err = json.Unmarshal(response, &policies)
-	if err != nil {
-		// something bad
-		reqLogger.Info("Error parsing json!", "Error", err, "Data", string(response))
-		return nil, err
+// apiClient returns management API client of instance authenticated as service account
+func (r *ReconcileRabbitmq) apiClient(cr *rabbitmqv1.Rabbitmq, serviceAccount basicAuthCredentials) (rabbitmqclient.Client, error) {
+	newAPIClient := r.newAPIClient
+	if newAPIClient == nil {
+		newAPIClient = rabbitmqclient.New
 	}
-
-	return policies, nil
+	return newAPIClient(rabbitmqclient.Options{
+		BaseURL:  r.apiServiceAddress(cr),
+		Username: serviceAccount.username,
+		Password: serviceAccount.password,
+	})
 }
 
-func (r *ReconcileRabbitmq) apiPolicyAdd(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret basicAuthCredentials, policyVHost string, policyData rabbitmqv1.RabbitmqPolicy) error {
-	reqLogger.Info("Adding " + policyData.Name + " to " + policyVHost + " vhost")
-	policyJSON, _ := json.Marshal(policyData)
-	url := r.apiServiceAddress(cr) + "/api/policies/" + policyVHost + "/" + policyData.Name
-	err := putRequest(url, secret, string(policyJSON))
-	if err != nil {
-		return err
+// podAPIClient returns management API client of one node, health checks are run on the node serving the request
+func (r *ReconcileRabbitmq) podAPIClient(pod *corev1.Pod, serviceAccount basicAuthCredentials) (rabbitmqclient.Client, error) {
+	newAPIClient := r.newAPIClient
+	if newAPIClient == nil {
+		newAPIClient = rabbitmqclient.New
 	}
-	return nil
+	return newAPIClient(rabbitmqclient.Options{
+		BaseURL:  "http://" + net.JoinHostPort(pod.Status.PodIP, "15672"),
+		Username: serviceAccount.username,
+		Password: serviceAccount.password,
+	})
 }
 
-func (r *ReconcileRabbitmq) apiPolicyRemove(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret basicAuthCredentials, policyVHost string, policyName string) error {
-	reqLogger.Info("Removing " + policyName + " from " + policyVHost + " vhost")
-	url := r.apiServiceAddress(cr) + "/api/policies/" + policyVHost + "/" + policyName
-	err := deleteRequest(url, secret, "")
+// getAPIClient reads service account secret and returns management API client of instance
+func (r *ReconcileRabbitmq) getAPIClient(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) (rabbitmqclient.Client, basicAuthCredentials, error) {
+	serviceAccount, err := r.getServiceAccountCredentials(reqLogger, cr, secretNames)
 	if err != nil {
-		return err
+		return nil, serviceAccount, err
 	}
-	return nil
-}
-
-// Users block
-
-func (r *ReconcileRabbitmq) apiUserList(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret basicAuthCredentials) ([]rabbitmqUserStruct, error) {
-	url := r.apiServiceAddress(cr) + "/api/users"
-
-	response, err := getRequest(url, secret)
-	if err != nil {
-		reqLogger.Info("Error while receiving user list", "Error", err)
-		return nil, err
-	}
-
-	var users []rabbitmqUserStruct
-	err = json.Unmarshal(response, &users)
-	if err != nil {
-		// something bad
-		reqLogger.Info("Error parsing json!", "Error", err, "Data", string(response))
-		return nil, err
-	}
-
-	return users, nil
-}
-
-func (r *ReconcileRabbitmq) apiUserAdd(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret basicAuthCredentials, user rabbitmqUserStruct) error {
-	userJSON, err := json.Marshal(user)
-	reqLogger.Info("Adding "+user.Name+" with tag: "+user.Tags, "JSON", string(userJSON))
-	if err != nil {
-		reqLogger.Info("Error while marshaling to JSON", "User", user.Name, "Error", err)
-	}
-	url := r.apiServiceAddress(cr) + "/api/users/" + user.Name
-	err = putRequest(url, secret, string(userJSON))
-	if err != nil {
-		reqLogger.Info("Error while putRequest", "User", user.Name, "Error", err)
-		return err
-	}
-	return nil
-}
-
-func (r *ReconcileRabbitmq) apiUserRemove(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret basicAuthCredentials, user rabbitmqUserStruct) error {
-	reqLogger.Info("Remove user " + user.Name)
-	url := r.apiServiceAddress(cr) + "/api/users/" + user.Name
-	err := deleteRequest(url, secret, "")
-	if err != nil {
-		return err
-	}
-	return nil
-}
 
-func (r *ReconcileRabbitmq) apiUserBulkRemove(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret basicAuthCredentials, users []string) error {
-	reqLogger.Info("Remove users", "bulk", users)
-	usersJSON, _ := json.Marshal(rabbitmqUsersListStruct{Users: users})
-	url := r.apiServiceAddress(cr) + "/api/users/bulk-delete"
-	err := deleteRequest(url, secret, string(usersJSON))
+	apiClient, err := r.apiClient(cr, serviceAccount)
 	if err != nil {
-		return err
+		return nil, serviceAccount, err
 	}
-	return nil
+	return apiClient, serviceAccount, nil
 }
@@ -0,0 +1,190 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// certificateRequeue certificates are not watched, issuing is polled
+const certificateRequeue = 10 * time.Second
+
+// certificateKind cert-manager API, types aren't vendored so unstructured objects are used
+var certificateKind = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// certificateDNSNames returns names of client, management and discovery services and of every pod under discovery service
+func certificateDNSNames(cr *rabbitmqv1.Rabbitmq, replicas int32) []string {
+	domains := []string{cr.Namespace, cr.Namespace + ".svc"}
+	if clusterDomain := cr.Namespace + "." + cr.Spec.RabbitmqK8SServiceDiscovery; clusterDomain != domains[1] {
+		domains = append(domains, clusterDomain)
+	}
+
+	var names []string
+	for _, service := range []string{cr.Name, cr.Name + "-api", cr.Name + "-discovery"} {
+		names = append(names, service)
+		for _, domain := range domains {
+			names = append(names, service+"."+domain)
+		}
+	}
+	for ordinal := int32(0); ordinal < replicas; ordinal++ {
+		pod := fmt.Sprintf("%s-%d.%s-discovery", cr.Name, ordinal, cr.Name)
+		for _, domain := range domains {
+			names = append(names, pod+"."+domain)
+		}
+	}
+	return names
+}
+
+// certificateSpec returns fields of Certificate spec managed by operator, other fields are left to users
+func certificateSpec(cr *rabbitmqv1.Rabbitmq, replicas int32) map[string]interface{} {
+	ssl := cr.Spec.RabbitmqSSL
+	var dnsNames []interface{}
+	for _, name := range certificateDNSNames(cr, replicas) {
+		dnsNames = append(dnsNames, name)
+	}
+	labels := map[string]interface{}{}
+	for key, value := range returnLabels(cr) {
+		labels[key] = value
+	}
+	spec := map[string]interface{}{
+		"secretName": ssl.ExitingSecret,
+		"dnsNames":   dnsNames,
+		"issuerRef": map[string]interface{}{
+			"name":  ssl.IssuerRef.Name,
+			"kind":  ssl.IssuerRef.Kind,
+			"group": ssl.IssuerRef.Group,
+		},
+		// secret is watched by instance label, renewed certificate is rolled out at once
+		"secretTemplate": map[string]interface{}{"labels": labels},
+	}
+	if ssl.InterNodeTLS {
+		// nodes present the certificate to each other as clients too
+		spec["usages"] = []interface{}{"digital signature", "key encipherment", "server auth", "client auth"}
+	}
+	return spec
+}
+
+// certificateTime parses timestamp from Certificate status
+func certificateTime(certificate *unstructured.Unstructured, field string) *metav1.Time {
+	value, found, _ := unstructured.NestedString(certificate.Object, "status", field)
+	if !found {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	t := metav1.NewTime(parsed)
+	return &t
+}
+
+// certificateReady returns Ready condition of Certificate, a condition observed for older spec is not ready
+func certificateReady(certificate *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		if generation, found, _ := unstructured.NestedInt64(condition, "observedGeneration"); found && generation < certificate.GetGeneration() {
+			return false, "certificate is being issued"
+		}
+		message, _ := condition["message"].(string)
+		return condition["status"] == string(corev1.ConditionTrue), message
+	}
+	return false, "certificate is being issued"
+}
+
+// reconcileCertificate creates cert-manager Certificate for cert.issuerRef and returns true when its secret is issued.
+// Pod template and replicas aren't increased until then, so pods never start with certificate missing their names
+func (r *ReconcileRabbitmq) reconcileCertificate(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, replicas int32) (bool, error) {
+	ssl := cr.Spec.RabbitmqSSL
+	if !ssl.Enabled || ssl.IssuerRef == nil {
+		cr.Status.Certificate = nil
+		return true, nil
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateKind)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, certificate)
+	if err != nil && apierrors.IsNotFound(err) {
+		reqLogger.Info("Creating Certificate", "Certificate.Namespace", cr.Namespace, "Certificate.Name", cr.Name, "Issuer", ssl.IssuerRef.Name)
+		certificate.SetName(cr.Name)
+		certificate.SetNamespace(cr.Namespace)
+		certificate.SetLabels(returnLabels(cr))
+		if err := unstructured.SetNestedField(certificate.Object, certificateSpec(cr, replicas), "spec"); err != nil {
+			return false, err
+		}
+		if err := controllerutil.SetControllerReference(cr, certificate, r.scheme); err != nil {
+			return false, err
+		}
+		if err := r.client.Create(context.TODO(), certificate); err != nil {
+			return false, err
+		}
+	} else if err != nil {
+		return false, err
+	} else {
+		spec, _, _ := unstructured.NestedMap(certificate.Object, "spec")
+		if spec == nil {
+			spec = map[string]interface{}{}
+		}
+		changed := false
+		for key, value := range certificateSpec(cr, replicas) {
+			if !reflect.DeepEqual(spec[key], value) {
+				spec[key] = value
+				changed = true
+			}
+		}
+		if changed {
+			reqLogger.Info("Updating Certificate", "Certificate.Namespace", cr.Namespace, "Certificate.Name", cr.Name)
+			if err := unstructured.SetNestedField(certificate.Object, spec, "spec"); err != nil {
+				return false, err
+			}
+			if err := r.client.Update(context.TODO(), certificate); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	cr.Status.Certificate = &rabbitmqv1.RabbitmqCertificateStatus{
+		Name:        cr.Name,
+		SecretName:  ssl.ExitingSecret,
+		NotAfter:    certificateTime(certificate, "notAfter"),
+		RenewalTime: certificateTime(certificate, "renewalTime"),
+	}
+
+	ready, message := certificateReady(certificate)
+	if !ready {
+		reqLogger.Info("Waiting for Certificate", "Certificate.Name", cr.Name, "Reason", message)
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionCertificateReady, corev1.ConditionFalse, "CertificateNotReady", message)
+		return false, nil
+	}
+
+	// cert-manager marks certificate ready before the secret reaches cache
+	if _, err := r.getSecret(ssl.ExitingSecret, cr.Namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionCertificateReady, corev1.ConditionFalse, "SecretMissing",
+				"secret "+ssl.ExitingSecret+" is not found")
+			return false, nil
+		}
+		return false, err
+	}
+
+	expiry := ""
+	if cr.Status.Certificate.NotAfter != nil {
+		expiry = "expires " + cr.Status.Certificate.NotAfter.UTC().Format(time.RFC3339)
+	}
+	setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionCertificateReady, corev1.ConditionTrue, "CertificateReady", expiry)
+	return true, nil
+}
@@ -0,0 +1,46 @@
+package rabbitmq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tekliner/rabbitmq-operator/pkg/apis"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestReconcileInstanceWaitsForCertificateSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cr := &rabbitmqv1.Rabbitmq{
+		ObjectMeta: metav1.ObjectMeta{Name: "rabbit", Namespace: "queues"},
+		Spec: rabbitmqv1.RabbitmqSpec{
+			RabbitmqReplicas: 3,
+			RabbitmqSSL:      rabbitmqv1.RabbitmqSSL{Enabled: true, IssuerRef: &rabbitmqv1.RabbitmqIssuerRef{Name: "ca"}},
+		},
+	}
+	cr.Default()
+	// cert-manager hasn't written the TLS secret yet
+	k8sClient := &fakeClient{secrets: map[string]*corev1.Secret{
+		"queues/rabbit-service-account": {Data: map[string][]byte{"username": []byte("sa"), "password": []byte("secret"), "cookie": []byte("cookie")}},
+		"queues/rabbit-credentials":     {Data: map[string][]byte{}},
+	}}
+	r := &ReconcileRabbitmq{client: k8sClient, scheme: scheme}
+
+	result, err := r.reconcileInstance(log, cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter != certificateRequeue {
+		t.Errorf("requeue after %v, want %v", result.RequeueAfter, certificateRequeue)
+	}
+	for _, created := range k8sClient.created {
+		if strings.HasPrefix(created, "StatefulSet/") {
+			t.Errorf("%s is created before the certificate is issued", created)
+		}
+	}
+}
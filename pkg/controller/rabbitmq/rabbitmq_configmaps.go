@@ -3,42 +3,68 @@ package rabbitmq
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/leekchan/gtf"
 	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// ConfigHashAnnotation of pod template, hash of rabbitmq.conf and enabled_plugins the pods were started with.
+// Configmap has the hash of its current data, pods have the hash of config they mounted
+const ConfigHashAnnotation = "rabbitmq.improvado.io/config-hash"
+
+// ConfigUpdatedAnnotation of configmap, time its config hash changed. Pods created later mounted the current config
+const ConfigUpdatedAnnotation = "rabbitmq.improvado.io/config-updated"
+
 type templateDataStruct struct {
-	Spec            rabbitmqv1.RabbitmqSpec
-	DefaultUser     string
-	DefaultPassword string
-	Watermark       string
+	Spec         rabbitmqv1.RabbitmqSpec
+	DefaultVhost string
+	Watermark    string
+	TLSPath      string
 }
 
+// defaultRabbitmqConfig has no credentials, default_user and default_pass are in conf.d, see reconcileDefaultUserSecret
 const defaultRabbitmqConfig = `# RabbitMQ operator templated config
-default_user = {{ .DefaultUser | default "rabbit" }}
-default_pass = {{ .DefaultPassword | default "rabbit" }}
-default_vhost = {{ .Spec.RabbitmqVhost | default "rabbit" }}
-
-cluster_formation.peer_discovery_backend  = {{ .Spec.RabbitmqK8SPeerDiscoveryBackend | default "rabbit_peer_discovery_k8s" }}
-cluster_formation.k8s.host = {{ .Spec.RabbitmqK8SHost | default "kubernetes.default.svc.cluster.local" }}
-cluster_formation.k8s.address_type = {{ .Spec.RabbitmqK8SAddrType | default "hostname" }}
-cluster_formation.node_cleanup.interval = {{ .Spec.RabbitmqClusterFormationNodeCleanup | default "10" }}
+default_vhost = {{ .DefaultVhost }}
+
+cluster_formation.peer_discovery_backend  = {{ .Spec.RabbitmqK8SPeerDiscoveryBackend }}
+cluster_formation.k8s.host = {{ .Spec.RabbitmqK8SHost }}
+cluster_formation.k8s.address_type = {{ .Spec.RabbitmqK8SAddrType }}
+cluster_formation.node_cleanup.interval = {{ .Spec.RabbitmqClusterFormationNodeCleanup }}
 cluster_formation.node_cleanup.only_log_warning = true
-cluster_partition_handling = {{ .Spec.RabbitmqClusterPartitionHandling | default "autoheal" }}
+cluster_partition_handling = {{ .Spec.RabbitmqClusterPartitionHandling }}
 loopback_users.guest = false
-hipe_compile = {{ .Spec.RabbitmqHipeCompile | default "false" }}
-vm_memory_high_watermark_paging_ratio = {{ .Spec.RabbitmqMemoryHighWatermarkPagingRatio | default "0.8" }}
+hipe_compile = {{ .Spec.RabbitmqHipeCompile }}
+vm_memory_high_watermark_paging_ratio = {{ .Spec.RabbitmqMemoryHighWatermarkPagingRatio }}
 vm_memory_high_watermark.absolute = {{ .Watermark }}
+{{- with .Spec.RabbitmqSSL }}{{ if .Enabled }}
+{{ if .DisablePlaintext }}
+listeners.tcp = none
+{{- end }}
+listeners.ssl.default = 5671
+ssl_options.cacertfile = {{ $.TLSPath }}/{{ .Cacertfile }}
+ssl_options.certfile = {{ $.TLSPath }}/{{ .Certfile }}
+ssl_options.keyfile = {{ $.TLSPath }}/{{ .Keyfile }}
+ssl_options.verify = {{ .Verify }}
+ssl_options.fail_if_no_peer_cert = {{ .FailIfNoPeerCert }}
+management.tcp.port = 15672
+management.ssl.port = 15671
+management.ssl.cacertfile = {{ $.TLSPath }}/{{ .Cacertfile }}
+management.ssl.certfile = {{ $.TLSPath }}/{{ .Certfile }}
+management.ssl.keyfile = {{ $.TLSPath }}/{{ .Keyfile }}
+{{- end }}{{ end }}
 `
 
 const defaultRabbitmqPlugins = `[
@@ -54,13 +80,65 @@ rabbitmq_shovel_management
 {{end}}].
 `
 
+// interNodeTLSConfig is ssl_dist_optfile of erlang distribution, the node is server for incoming connections and client for outgoing ones
+const interNodeTLSConfig = `[
+  {server, [
+    {cacertfile, "{{ .TLSPath }}/{{ .Spec.RabbitmqSSL.Cacertfile }}"},
+    {certfile, "{{ .TLSPath }}/{{ .Spec.RabbitmqSSL.Certfile }}"},
+    {keyfile, "{{ .TLSPath }}/{{ .Spec.RabbitmqSSL.Keyfile }}"},
+    {secure_renegotiate, true},
+    {verify, verify_peer},
+    {fail_if_no_peer_cert, true}
+  ]},
+  {client, [
+    {cacertfile, "{{ .TLSPath }}/{{ .Spec.RabbitmqSSL.Cacertfile }}"},
+    {certfile, "{{ .TLSPath }}/{{ .Spec.RabbitmqSSL.Certfile }}"},
+    {keyfile, "{{ .TLSPath }}/{{ .Spec.RabbitmqSSL.Keyfile }}"},
+    {secure_renegotiate, true},
+    {verify, verify_peer}
+  ]}
+].
+`
+
+// legacyCookieKey of configmap, pods created by older versions read the cookie from it in RABBITMQ_ERLANG_COOKIE
+const legacyCookieKey = ".erlang.cookie"
+
+// initRabbitmqScript cookie is passed from secret in RABBITMQ_ERLANG_COOKIE, file left by older versions is removed
 const initRabbitmqScript = `# RabbitMQ Init script
 rm -f /var/lib/rabbitmq/.erlang.cookie
 cp /rabbit-config/* /etc/rabbitmq
-cp /rabbit-config/.* /etc/rabbitmq
-chmod 600 /etc/rabbitmq/.erlang.cookie  
 `
 
+// podUsesLegacyCookie is true when rabbitmq container of pod reads the cookie from configmap
+func podUsesLegacyCookie(pod corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Key == legacyCookieKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// legacyCookieInUse is true until rollout replaced every pod created by older versions,
+// restarted container of such pod can't start without the configmap key
+func (r *ReconcileRabbitmq) legacyCookieInUse(cr *rabbitmqv1.Rabbitmq) (bool, error) {
+	if cr.Status.Rollout != nil {
+		return true, nil
+	}
+	pods, err := r.listPods(cr)
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range pods {
+		if podUsesLegacyCookie(pod) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func applyDataOnTemplate(reqLogger logr.Logger, templateContent string, cr templateDataStruct) (string, error) {
 	var buf bytes.Buffer
 	templateObj, err := gtf.New("config").Parse(templateContent)
@@ -74,7 +152,31 @@ func applyDataOnTemplate(reqLogger logr.Logger, templateContent string, cr templ
 	return buf.String(), err
 }
 
-func (r *ReconcileRabbitmq) reconcileConfigMap(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) (reconcile.Result, error) {
+// configHash returns hash of files which need restart of rabbitmq to be applied
+func configHash(configmap *corev1.ConfigMap) string {
+	hash := sha256.New()
+	for _, key := range []string{"rabbitmq.conf", "enabled_plugins", interNodeTLSConfigFile} {
+		value, found := configmap.Data[key]
+		if !found {
+			continue
+		}
+		hash.Write([]byte(key + "\x00" + value + "\x00"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// podConfigHash returns config hash for pod template, with OnNextRestart running pods keep hash of the config they were started with
+func podConfigHash(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, statefulset *v1.StatefulSet, currentHash string) string {
+	appliedHash, found := statefulset.Spec.Template.Annotations[ConfigHashAnnotation]
+	if !found || appliedHash == currentHash || cr.Spec.RabbitmqConfigUpdatePolicy != rabbitmqv1.RabbitmqConfigUpdateOnNextRestart {
+		return currentHash
+	}
+	reqLogger.Info("Config changed, it is applied on next restart of pods", "Hash", currentHash)
+	return appliedHash
+}
+
+// reconcileConfigMap renders config and returns the configmap stored in cluster
+func (r *ReconcileRabbitmq) reconcileConfigMap(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) (*corev1.ConfigMap, error) {
 	reqLogger.Info("Started reconciling Configmap", "ConfigMap.Namespace", cr.Namespace, "ConfigMap.Name", cr.Name)
 	var err error
 	var templateData templateDataStruct
@@ -89,42 +191,18 @@ func (r *ReconcileRabbitmq) reconcileConfigMap(reqLogger logr.Logger, cr *rabbit
 		templateData.Watermark = strconv.FormatInt(watermarkLimitBytes, 10)
 	}
 
-	secretObj := corev1.Secret{}
-	reqLogger.Info("Configmap receiving secret", "ConfigMap.Namespace", cr.Namespace, "ConfigMap.Name", cr.Name, "Secret name", secretNames.ServiceAccount)
-	secretObj, err = r.getSecret(secretNames.ServiceAccount, cr.Namespace)
-	if err != nil {
-		reqLogger.Info("Configmap can't receive secret", "ConfigMap.Namespace", cr.Namespace, "ConfigMap.Name", cr.Name, "Secret name", secretNames.ServiceAccount)
-		return reconcile.Result{}, err
-	}
-
-	defaultUsername := string(secretObj.Data["username"])
-	if defaultUsername == "" {
-		reqLogger.Info("Empty service username", "ConfigMap.Namespace", cr.Namespace, "ConfigMap.Name", cr.Name)
-		return reconcile.Result{}, err
-	}
-	templateData.DefaultUser = defaultUsername
-	defaultPassword := string(secretObj.Data["password"])
-	if defaultPassword == "" {
-		reqLogger.Info("Empty service password", "ConfigMap.Namespace", cr.Namespace, "ConfigMap.Name", cr.Name)
-		return reconcile.Result{}, err
-	}
-	templateData.DefaultPassword = defaultPassword
-	cookieData := string(secretObj.Data["cookie"])
-	if cookieData == "" {
-		reqLogger.Info("Empty cookie data", "ConfigMap.Namespace", cr.Namespace, "ConfigMap.Name", cr.Name)
-		return reconcile.Result{}, err
-	}
-	reqLogger.Info("Configmap decoded secret", "ConfigMap.Namespace", cr.Namespace, "ConfigMap.Name", cr.Name, "Secret cookie", cookieData)
-
 	templateData.Spec = cr.Spec
+	// default_vhost isn't written into stored spec, rabbitmq.conf gets the default
+	templateData.DefaultVhost = cr.DefaultVhost()
+	templateData.TLSPath = tlsPath
 
 	resultConfig, err := applyDataOnTemplate(reqLogger, defaultRabbitmqConfig, templateData)
 	if err != nil {
-		return reconcile.Result{}, err
+		return nil, err
 	}
 	resultPlugins, err := applyDataOnTemplate(reqLogger, defaultRabbitmqPlugins, templateData)
 	if err != nil {
-		return reconcile.Result{}, err
+		return nil, err
 	}
 
 	configmap := &corev1.ConfigMap{
@@ -136,14 +214,21 @@ func (r *ReconcileRabbitmq) reconcileConfigMap(reqLogger logr.Logger, cr *rabbit
 		Data: map[string]string{
 			"rabbitmq.conf":   resultConfig,
 			"enabled_plugins": resultPlugins,
-			".erlang.cookie":  cookieData,
 			"init.sh":         initRabbitmqScript,
 		},
 	}
 
+	if cr.Spec.RabbitmqSSL.Enabled && cr.Spec.RabbitmqSSL.InterNodeTLS {
+		resultInterNodeTLS, err := applyDataOnTemplate(reqLogger, interNodeTLSConfig, templateData)
+		if err != nil {
+			return nil, err
+		}
+		configmap.Data[interNodeTLSConfigFile] = resultInterNodeTLS
+	}
+
 	if err := controllerutil.SetControllerReference(cr, configmap, r.scheme); err != nil {
 		reqLogger.Info("Configmap can't set controller reference", "ConfigMap.Namespace", configmap.Namespace, "ConfigMap.Name", configmap.Name)
-		return reconcile.Result{}, err
+		return nil, err
 	}
 
 	found := &corev1.ConfigMap{}
@@ -156,12 +241,25 @@ func (r *ReconcileRabbitmq) reconcileConfigMap(reqLogger logr.Logger, cr *rabbit
 
 		if err != nil {
 			reqLogger.Info("Creating ConfigMap error", "ConfigMap.Namespace", configmap.Namespace, "ConfigMap.Name", configmap.Name)
-			return reconcile.Result{}, err
+			return nil, err
 		}
 
 	} else if err != nil {
 		reqLogger.Info("Unknown error while getting ConfigMap", "ConfigMap.Namespace", configmap.Namespace, "ConfigMap.Name", configmap.Name)
-		return reconcile.Result{}, err
+		return nil, err
+	}
+
+	// cookie of older versions is removed when no pod reads it
+	if cookie, exists := found.Data[legacyCookieKey]; exists {
+		inUse, err := r.legacyCookieInUse(cr)
+		if err != nil {
+			return nil, err
+		}
+		if inUse {
+			configmap.Data[legacyCookieKey] = cookie
+		} else {
+			reqLogger.Info("Removing cookie from configmap", "ConfigMap.Namespace", configmap.Namespace, "ConfigMap.Name", configmap.Name)
+		}
 	}
 
 	if !reflect.DeepEqual(found.Data, configmap.Data) {
@@ -169,11 +267,57 @@ func (r *ReconcileRabbitmq) reconcileConfigMap(reqLogger logr.Logger, cr *rabbit
 		found.Data = configmap.Data
 	}
 
+	if hash := configHash(found); found.Annotations[ConfigHashAnnotation] != hash {
+		found.Annotations = mergeMaps(found.Annotations, map[string]string{
+			ConfigHashAnnotation:    hash,
+			ConfigUpdatedAnnotation: time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+
 	if err = r.client.Update(context.TODO(), found); err != nil {
 		reqLogger.Info("Configmap can't be updated", "ConfigMap.Namespace", configmap.Namespace, "ConfigMap.Name", configmap.Name)
-		return reconcile.Result{}, err
+		return nil, err
 	}
 
 	reqLogger.Info("Configmap successfuly reconciled", "ConfigMap.Namespace", configmap.Namespace, "ConfigMap.Name", configmap.Name)
-	return reconcile.Result{}, nil
+	return found, nil
+}
+
+// reconcileAppliedConfig sets ConfigApplied condition from config hash of every pod. Pod created after config was updated
+// mounted the current config and is annotated with its hash, with OnNextRestart pod template keeps the old hash
+func (r *ReconcileRabbitmq) reconcileAppliedConfig(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, configmap *corev1.ConfigMap) error {
+	currentHash := configmap.Annotations[ConfigHashAnnotation]
+	updated, updatedErr := time.Parse(time.RFC3339, configmap.Annotations[ConfigUpdatedAnnotation])
+
+	pods, err := r.listPods(cr)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, pod := range pods {
+		if pod.Annotations[ConfigHashAnnotation] == currentHash {
+			continue
+		}
+		if updatedErr == nil && pod.DeletionTimestamp == nil && pod.CreationTimestamp.Time.After(updated) {
+			reqLogger.Info("Pod started with current config", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name, "Hash", currentHash)
+			pod.Annotations = mergeMaps(pod.Annotations, map[string]string{ConfigHashAnnotation: currentHash})
+			if err := r.client.Update(context.TODO(), &pod); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		pending = append(pending, pod.Name)
+	}
+
+	switch {
+	case len(pods) == 0:
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionConfigApplied, corev1.ConditionUnknown, "NoPods", "")
+	case len(pending) == 0:
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionConfigApplied, corev1.ConditionTrue, "ConfigApplied", "")
+	default:
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionConfigApplied, corev1.ConditionFalse, "PendingRestart",
+			"pods "+strings.Join(pending, ", ")+" run old config, it is applied when they are restarted, configUpdatePolicy is "+cr.Spec.RabbitmqConfigUpdatePolicy)
+	}
+	return nil
 }
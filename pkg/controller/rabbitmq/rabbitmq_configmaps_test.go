@@ -0,0 +1,119 @@
+package rabbitmq
+
+import (
+	"testing"
+	"time"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileAppliedConfig(t *testing.T) {
+	updated := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	configmap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		ConfigHashAnnotation:    "new",
+		ConfigUpdatedAnnotation: updated.Format(time.RFC3339),
+	}}}
+	pod := func(name string, hash string, created time.Time) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Annotations:       map[string]string{ConfigHashAnnotation: hash},
+			CreationTimestamp: metav1.NewTime(created),
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		pods        []corev1.Pod
+		wantStatus  corev1.ConditionStatus
+		wantUpdated []string
+	}{
+		{
+			name:       "pods run current config",
+			pods:       []corev1.Pod{pod("rabbit-0", "new", updated.Add(-time.Hour)), pod("rabbit-1", "new", updated.Add(-time.Hour))},
+			wantStatus: corev1.ConditionTrue,
+		},
+		{
+			name:        "pods restarted after config change from template with old hash",
+			pods:        []corev1.Pod{pod("rabbit-0", "old", updated.Add(time.Minute)), pod("rabbit-1", "new", updated.Add(-time.Hour))},
+			wantStatus:  corev1.ConditionTrue,
+			wantUpdated: []string{"rabbit-0"},
+		},
+		{
+			name:       "pod started before config change",
+			pods:       []corev1.Pod{pod("rabbit-0", "old", updated.Add(time.Minute)), pod("rabbit-1", "old", updated.Add(-time.Hour))},
+			wantStatus: corev1.ConditionFalse,
+			// rabbit-0 is annotated even though rabbit-1 is still pending
+			wantUpdated: []string{"rabbit-0"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			k8sClient := &fakeClient{pods: test.pods}
+			r := &ReconcileRabbitmq{client: k8sClient}
+			cr := &rabbitmqv1.Rabbitmq{ObjectMeta: metav1.ObjectMeta{Name: "rabbit", Namespace: "queues"}}
+			cr.Spec.RabbitmqConfigUpdatePolicy = rabbitmqv1.RabbitmqConfigUpdateOnNextRestart
+
+			if err := r.reconcileAppliedConfig(log, cr, configmap); err != nil {
+				t.Fatal(err)
+			}
+			if status, _ := conditionStatus(cr, rabbitmqv1.RabbitmqConditionConfigApplied); status != test.wantStatus {
+				t.Errorf("ConfigApplied = %s, want %s", status, test.wantStatus)
+			}
+			if len(k8sClient.updatedPods) != len(test.wantUpdated) {
+				t.Fatalf("updated pods = %d, want %v", len(k8sClient.updatedPods), test.wantUpdated)
+			}
+			for i, pod := range k8sClient.updatedPods {
+				if pod.Name != test.wantUpdated[i] || pod.Annotations[ConfigHashAnnotation] != "new" {
+					t.Errorf("updated pod %s with hash %s", pod.Name, pod.Annotations[ConfigHashAnnotation])
+				}
+			}
+		})
+	}
+}
+
+func TestLegacyCookieInUse(t *testing.T) {
+	legacyPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rabbit-1"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: rabbitmqContainerName, Env: []corev1.EnvVar{{
+			Name:      "RABBITMQ_ERLANG_COOKIE",
+			ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "rabbit"}, Key: legacyCookieKey}},
+		}}}}},
+	}
+	currentPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rabbit-0"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: rabbitmqContainerName, Env: []corev1.EnvVar{{
+			Name:      "RABBITMQ_ERLANG_COOKIE",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "rabbit-service-account"}, Key: "cookie"}},
+		}}}}},
+	}
+
+	tests := []struct {
+		name    string
+		pods    []corev1.Pod
+		rollout *rabbitmqv1.RabbitmqRolloutStatus
+		want    bool
+	}{
+		{name: "pod of older version", pods: []corev1.Pod{currentPod, legacyPod}, want: true},
+		{name: "rollout in progress", pods: []corev1.Pod{currentPod}, rollout: &rabbitmqv1.RabbitmqRolloutStatus{Revision: "new"}, want: true},
+		{name: "rollout complete", pods: []corev1.Pod{currentPod}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &ReconcileRabbitmq{client: &fakeClient{pods: test.pods}}
+			cr := &rabbitmqv1.Rabbitmq{ObjectMeta: metav1.ObjectMeta{Name: "rabbit", Namespace: "queues"}}
+			cr.Status.Rollout = test.rollout
+
+			inUse, err := r.legacyCookieInUse(cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inUse != test.want {
+				t.Errorf("legacyCookieInUse = %v, want %v", inUse, test.want)
+			}
+		})
+	}
+}
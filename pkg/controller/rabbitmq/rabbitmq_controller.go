@@ -5,17 +5,18 @@ import (
 	"os"
 	"reflect"
 	"strconv"
-	"time"
 
 	"github.com/getsentry/raven-go"
+	"github.com/go-logr/logr"
 	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -50,7 +51,15 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileRabbitmq{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	reconciler := &ReconcileRabbitmq{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		log.Error(err, "Kubernetes clientset is not available, scale down and volume expansion are disabled")
+		return reconciler
+	}
+	reconciler.clientset = clientset
+	reconciler.podExecutor = newPodExecutor(mgr.GetConfig(), clientset)
+	return reconciler
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -133,6 +142,18 @@ type ReconcileRabbitmq struct {
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
 	scheme *runtime.Scheme
+
+	// newAPIClient creates management API clients, rabbitmqclient.New if nil
+	newAPIClient func(options rabbitmqclient.Options) (rabbitmqclient.Client, error)
+
+	// uncached client for cluster scoped objects, manager cache is limited to operator namespace
+	clientset kubernetes.Interface
+
+	// runs rabbitmq CLI tools in pods
+	podExecutor podExecutor
+
+	// failed management syncs of every Rabbitmq, used for requeue backoff
+	managementBackoff managementBackoff
 }
 
 // Reconcile reads that state of the cluster for a Rabbitmq object and makes changes based on the state read
@@ -198,6 +219,35 @@ func (r *ReconcileRabbitmq) Reconcile(request reconcile.Request) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
+	// defaults are written by mutating webhook, set them here too if webhook is not installed
+	instance.Default()
+
+	// status can't be written after the last finalizer is removed
+	if !instance.ObjectMeta.DeletionTimestamp.IsZero() {
+		result, err := r.finalizeRabbitmq(reqLogger, instance)
+		if err != nil {
+			raven.CaptureErrorAndWait(err, nil)
+		}
+		return result, err
+	}
+
+	originalStatus := instance.Status.DeepCopy()
+
+	result, err := r.reconcileInstance(reqLogger, instance)
+
+	// status is written even if reconcile failed, so the error is visible in CR
+	if statusErr := r.updateStatus(reqLogger, instance, originalStatus, err); statusErr != nil {
+		reqLogger.Info("Status update error", "Error", statusErr.Error())
+		raven.CaptureErrorAndWait(statusErr, nil)
+		if err == nil {
+			return reconcile.Result{}, statusErr
+		}
+	}
+
+	return result, err
+}
+
+func (r *ReconcileRabbitmq) reconcileInstance(reqLogger logr.Logger, instance *rabbitmqv1.Rabbitmq) (reconcile.Result, error) {
 	// secrets used for API requests and user control
 	reqLogger.Info("Reconciling secrets")
 	secretNames, err := r.reconcileSecrets(reqLogger, instance)
@@ -206,6 +256,22 @@ func (r *ReconcileRabbitmq) Reconcile(request reconcile.Request) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
+	// default user credentials are mounted from secret, rabbitmq.conf in configmap has none
+	if err := r.reconcileDefaultUserSecret(reqLogger, instance, secretNames); err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+
+	// configmap is mounted by pods, its hash in pod template restarts pods when config changes
+	reqLogger.Info("Reconciling configmap")
+
+	configmap, err := r.reconcileConfigMap(reqLogger, instance)
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+	currentConfigHash := configHash(configmap)
+
 	statefulset := newStatefulSet(instance, secretNames)
 	if err := controllerutil.SetControllerReference(instance, statefulset, r.scheme); err != nil {
 		raven.CaptureErrorAndWait(err, nil)
@@ -214,7 +280,58 @@ func (r *ReconcileRabbitmq) Reconcile(request reconcile.Request) (reconcile.Resu
 
 	statefulsetFound := &v1.StatefulSet{}
 	err = r.client.Get(context.TODO(), types.NamespacedName{Name: statefulset.Name, Namespace: statefulset.Namespace}, statefulsetFound)
-	if err != nil && errors.IsNotFound(err) {
+	if err != nil && !errors.IsNotFound(err) {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+	statefulsetExists := err == nil
+
+	statefulset.Spec.Template.Annotations[ConfigHashAnnotation] = podConfigHash(reqLogger, instance, statefulsetFound, currentConfigHash)
+	// nodes are removed from cluster before replicas are reduced
+	replicas := statefulsetReplicas(instance, statefulsetFound)
+	statefulset.Spec.Replicas = &replicas
+
+	// issued certificate covers every pod, pod template and new pods wait for it
+	certificateReady, err := r.reconcileCertificate(reqLogger, instance, replicas)
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+	certificateResult := reconcile.Result{}
+	if !certificateReady {
+		certificateResult.RequeueAfter = certificateRequeue
+	}
+
+	// certificate is read on start, renewed one is applied by restarting pods. Secret of certificate being issued
+	// may be missing or empty, template keeps the hash it has
+	if certificateReady {
+		tlsHash, err := r.tlsSecretHash(reqLogger, instance)
+		if err != nil {
+			raven.CaptureErrorAndWait(err, nil)
+			return reconcile.Result{}, err
+		}
+		if tlsHash != "" {
+			statefulset.Spec.Template.Annotations[TLSHashAnnotation] = tlsHash
+		}
+	} else if tlsHash, found := statefulsetFound.Spec.Template.Annotations[TLSHashAnnotation]; found {
+		statefulset.Spec.Template.Annotations[TLSHashAnnotation] = tlsHash
+	}
+
+	// exporter reads service account credentials on start, rotated password is applied by restarting pods
+	if exporterRestartedOnRotation(instance) {
+		serviceAccount, err := r.getServiceAccountCredentials(reqLogger, instance, secretNames)
+		if err != nil {
+			raven.CaptureErrorAndWait(err, nil)
+			return reconcile.Result{}, err
+		}
+		statefulset.Spec.Template.Annotations[ExporterUserAnnotation] = serviceAccount.username
+	}
+
+	if !statefulsetExists {
+		if !certificateReady {
+			reqLogger.Info("Waiting for certificate before creating statefulset", "statefulset.Namespace", statefulset.Namespace, "statefulset.Name", statefulset.Name)
+			return certificateResult, nil
+		}
 		reqLogger.Info("Creating a new statefulset", "statefulset.Namespace", statefulset.Namespace, "statefulset.Name", statefulset.Name)
 		err = r.client.Create(context.TODO(), statefulset)
 		if err != nil {
@@ -224,15 +341,48 @@ func (r *ReconcileRabbitmq) Reconcile(request reconcile.Request) (reconcile.Resu
 
 		// statefulset created successfully - don't requeue
 		return reconcile.Result{}, nil
-	} else if err != nil {
+	}
+
+	// statefulset deleted for volume expansion is created again when it is gone, new one adopts the pods
+	if statefulsetFound.DeletionTimestamp != nil {
+		reqLogger.Info("Waiting for statefulset deletion", "statefulset.Namespace", statefulsetFound.Namespace, "statefulset.Name", statefulsetFound.Name)
+		return reconcile.Result{RequeueAfter: statefulsetDeletionRequeue}, nil
+	}
+
+	recreate, err := r.expandVolumes(reqLogger, instance, statefulsetFound)
+	if err != nil {
 		raven.CaptureErrorAndWait(err, nil)
 		return reconcile.Result{}, err
 	}
+	if recreate {
+		return reconcile.Result{RequeueAfter: statefulsetDeletionRequeue}, nil
+	}
+
+	// pod management policy is immutable, statefulset created with OrderedReady is recreated and new one adopts the pods
+	if statefulsetFound.Spec.PodManagementPolicy != statefulset.Spec.PodManagementPolicy {
+		reqLogger.Info("Recreating statefulset to change pod management policy", "statefulset.Namespace", statefulsetFound.Namespace, "statefulset.Name", statefulsetFound.Name, "PodManagementPolicy", statefulset.Spec.PodManagementPolicy)
+		if err := r.client.Delete(context.TODO(), statefulsetFound, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+			raven.CaptureErrorAndWait(err, nil)
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: statefulsetDeletionRequeue}, nil
+	}
+
+	// pods read certificate on start, template changes and new pods wait until it covers every pod,
+	// the rest of the cluster is reconciled meanwhile
+	if !certificateReady {
+		reqLogger.Info("Certificate is not ready, keeping statefulset template", "statefulset.Namespace", statefulsetFound.Namespace, "statefulset.Name", statefulsetFound.Name)
+		statefulset.Spec.Template = statefulsetFound.Spec.Template
+		if statefulsetFound.Spec.Replicas != nil && *statefulset.Spec.Replicas > *statefulsetFound.Spec.Replicas {
+			statefulset.Spec.Replicas = statefulsetFound.Spec.Replicas
+		}
+	}
 
 	if !reflect.DeepEqual(statefulsetFound.Spec, statefulset.Spec) {
 		statefulsetFound.Spec.Replicas = statefulset.Spec.Replicas
 		statefulsetFound.Spec.Template = statefulset.Spec.Template
 		statefulsetFound.Spec.Selector = statefulset.Spec.Selector
+		statefulsetFound.Spec.UpdateStrategy = statefulset.Spec.UpdateStrategy
 	}
 
 	if !reflect.DeepEqual(statefulsetFound.Annotations, statefulset.Annotations) {
@@ -250,6 +400,12 @@ func (r *ReconcileRabbitmq) Reconcile(request reconcile.Request) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
+	// pods have hash of the config they mounted, template keeps old one with OnNextRestart
+	if err := r.reconcileAppliedConfig(reqLogger, instance, configmap); err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+
 	// creating services
 	reqLogger.Info("Reconciling services")
 
@@ -272,11 +428,9 @@ func (r *ReconcileRabbitmq) Reconcile(request reconcile.Request) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
-	// configmap
-	reqLogger.Info("Reconciling configmap")
-
-	_, err = r.reconcileConfigMap(reqLogger, instance, secretNames)
-	if err != nil {
+	// binding secrets of RabbitmqUsers are copied to namespaces of applications
+	reqLogger.Info("Reconciling binding secret copies")
+	if err := r.reconcileBindingCopies(reqLogger, instance); err != nil {
 		raven.CaptureErrorAndWait(err, nil)
 		return reconcile.Result{}, err
 	}
@@ -299,40 +453,6 @@ func (r *ReconcileRabbitmq) Reconcile(request reconcile.Request) (reconcile.Resu
 		}
 	}
 
-	// set policies
-	reqLogger.Info("Setting up policies")
-	timeoutPolicies, _ := time.ParseDuration("30")
-	timeoutFlagPolicies := false
-	ctxPolicies, ctxPoliciesCancelTimeout := context.WithTimeout(context.Background(), timeoutPolicies)
-	defer ctxPoliciesCancelTimeout()
-	go r.setPolicies(ctxPolicies, reqLogger, instance, secretNames)
-	for {
-		if timeoutFlagPolicies {
-			break
-		}
-		select {
-		case <-ctxPolicies.Done():
-			timeoutFlagPolicies = true
-		}
-	}
-
-	// set additional users
-	reqLogger.Info("Setting up additional users")
-	timeoutUsers, _ := time.ParseDuration("30")
-	timeoutFlagUsers := false
-	ctxUsers, ctxUsersCancelTimeout := context.WithTimeout(context.Background(), timeoutUsers)
-	defer ctxUsersCancelTimeout()
-	go r.syncUsersCredentials(ctxUsers, reqLogger, instance, secretNames)
-	for {
-		if timeoutFlagUsers {
-			break
-		}
-		select {
-		case <-ctxUsers.Done():
-			timeoutFlagUsers = true
-		}
-	}
-
 	// reconcile PodDisruptionBudget
 	reqLogger.Info("Reconciling PodDisruptionBudget")
 
@@ -348,10 +468,55 @@ func (r *ReconcileRabbitmq) Reconcile(request reconcile.Request) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{}, nil
+	// pods with old revision are restarted one by one
+	reqLogger.Info("Reconciling rollout")
+	rolloutResult, err := r.reconcileRollout(reqLogger, instance, secretNames, statefulsetFound)
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+
+	// nodes are drained and forgotten on scale down, quorum queues are grown on scale up
+	reqLogger.Info("Reconciling scaling")
+	scalingResult, err := r.reconcileScaling(reqLogger, instance, secretNames, statefulsetFound)
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+
+	// service account password and erlang cookie are rotated on request or by rotationPeriod
+	reqLogger.Info("Reconciling credentials rotation")
+	rotationResult, err := r.reconcileRotation(reqLogger, instance, secretNames, statefulsetFound)
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+
+	reqLogger.Info("Reconciling volume expansion status")
+	volumesResult, err := r.reconcileVolumeExpansionStatus(reqLogger, instance, statefulsetFound)
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+
+	// policies and users are synced through management API when kubernetes resources are in place
+	managementResult := r.reconcileManagement(reqLogger, instance, secretNames, statefulsetFound)
+	return mergeResults(certificateResult, rolloutResult, scalingResult, rotationResult, volumesResult, managementResult), nil
 
 }
 
+// mergeResults returns result which requeues the soonest
+func mergeResults(results ...reconcile.Result) reconcile.Result {
+	merged := reconcile.Result{}
+	for _, result := range results {
+		merged.Requeue = merged.Requeue || result.Requeue
+		if result.RequeueAfter > 0 && (merged.RequeueAfter == 0 || result.RequeueAfter < merged.RequeueAfter) {
+			merged.RequeueAfter = result.RequeueAfter
+		}
+	}
+	return merged
+}
+
 func appendNodeVariables(env []corev1.EnvVar, cr *rabbitmqv1.Rabbitmq) []corev1.EnvVar {
 	return append(env,
 		corev1.EnvVar{
@@ -392,52 +557,37 @@ func newStatefulSet(cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) *v1.Sta
 		affinity = cr.Spec.RabbitmqAffinity
 	}
 
-	defaultCPURequest := resource.MustParse("100m")
-	defaultMemoryRequest := resource.MustParse("512Mi")
-	defaultCPULimit := resource.MustParse("300m")
-	defaultMemoryLimit := resource.MustParse("512Mi")
-
-	if cr.Spec.RabbitmqPodRequests == nil {
-		cr.Spec.RabbitmqPodRequests = corev1.ResourceList{}
-	}
-
-	if _, ok := cr.Spec.RabbitmqPodRequests[corev1.ResourceCPU]; !ok {
-		cr.Spec.RabbitmqPodRequests[corev1.ResourceCPU] = defaultCPURequest
-	}
-
-	if _, ok := cr.Spec.RabbitmqPodRequests[corev1.ResourceMemory]; !ok {
-		cr.Spec.RabbitmqPodRequests[corev1.ResourceMemory] = defaultMemoryRequest
-	}
-
-	if cr.Spec.RabbitmqPodLimits == nil {
-		cr.Spec.RabbitmqPodLimits = corev1.ResourceList{}
-	}
-
-	if _, ok := cr.Spec.RabbitmqPodLimits[corev1.ResourceCPU]; !ok {
-		cr.Spec.RabbitmqPodLimits[corev1.ResourceCPU] = defaultCPULimit
-	}
-
-	if _, ok := cr.Spec.RabbitmqPodLimits[corev1.ResourceMemory]; !ok {
-		cr.Spec.RabbitmqPodLimits[corev1.ResourceMemory] = defaultMemoryLimit
-	}
-
 	// container with rabbitmq
 	rabbitmqContainer := corev1.Container{
 		Name:  "rabbitmq",
 		Image: cr.Spec.K8SImage.Name + ":" + cr.Spec.K8SImage.Tag,
-		Env: append(appendNodeVariables(cr.Spec.K8SENV, cr), corev1.EnvVar{
+		Env: append(append(appendNodeVariables(cr.Spec.K8SENV, cr), corev1.EnvVar{
 			Name:      "RABBITMQ_ERLANG_COOKIE",
-			ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: cr.Name}, Key: ".erlang.cookie"}},
-		}),
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretNames.ServiceAccount}, Key: "cookie"}},
+		}), interNodeTLSEnv(cr)...),
 		Resources: corev1.ResourceRequirements{
 			Requests: cr.Spec.RabbitmqPodRequests,
 			Limits:   cr.Spec.RabbitmqPodLimits,
 		},
+		ReadinessProbe: readinessProbe(cr),
+		LivenessProbe:  livenessProbe(cr),
+		Lifecycle: &corev1.Lifecycle{
+			PreStop: preStopHandler(cr),
+		},
 		VolumeMounts: []corev1.VolumeMount{
 			{
 				Name:      "rabbit-etc",
 				MountPath: "/etc/rabbitmq",
 			},
+			{
+				Name:      "podinfo",
+				MountPath: podInfoPath,
+			},
+			{
+				Name:      "rabbit-default-user",
+				MountPath: "/etc/rabbitmq/conf.d",
+				ReadOnly:  true,
+			},
 			{
 				Name:      "rabbit-data",
 				MountPath: "/var/lib/rabbitmq",
@@ -449,19 +599,22 @@ func newStatefulSet(cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) *v1.Sta
 		},
 	}
 
+	if cr.Spec.RabbitmqSSL.Enabled {
+		rabbitmqContainer.VolumeMounts = append(rabbitmqContainer.VolumeMounts, corev1.VolumeMount{
+			Name:      tlsVolumeName,
+			MountPath: tlsPath,
+			ReadOnly:  true,
+		})
+	}
+
 	podContainers = append(podContainers, rabbitmqContainer)
 
 	// if prometheus exporter enabled add additional container to pod
 	if cr.Spec.RabbitmqPrometheusExporterPort > 0 {
 
-		exporterImageAndTag := "kbudde/rabbitmq-exporter:latest"
-		if cr.Spec.RabbitmqPrometheusImage != "" {
-			exporterImageAndTag = cr.Spec.RabbitmqPrometheusImage
-		}
-
 		exporterContainer := corev1.Container{
 			Name:            "prometheus-exporter",
-			Image:           exporterImageAndTag,
+			Image:           cr.Spec.RabbitmqPrometheusImage,
 			ImagePullPolicy: corev1.PullIfNotPresent,
 			Env: []corev1.EnvVar{
 				{
@@ -513,10 +666,24 @@ func newStatefulSet(cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) *v1.Sta
 					},
 				},
 			},
-			Containers:   podContainers,
-			Tolerations:  cr.Spec.Tolerations,
-			NodeSelector: cr.Spec.NodeSelector,
+			Containers:                    podContainers,
+			Tolerations:                   cr.Spec.Tolerations,
+			NodeSelector:                  cr.Spec.NodeSelector,
+			TerminationGracePeriodSeconds: cr.Spec.RabbitmqTerminationGracePeriodSeconds,
 			Volumes: []corev1.Volume{
+				{
+					Name: "podinfo",
+					VolumeSource: corev1.VolumeSource{
+						DownwardAPI: &corev1.DownwardAPIVolumeSource{
+							Items: []corev1.DownwardAPIVolumeFile{
+								{
+									Path:     "annotations",
+									FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations"},
+								},
+							},
+						},
+					},
+				},
 				{
 					Name: "rabbit-config",
 					VolumeSource: corev1.VolumeSource{
@@ -533,14 +700,25 @@ func newStatefulSet(cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) *v1.Sta
 						EmptyDir: &corev1.EmptyDirVolumeSource{},
 					},
 				},
+				{
+					Name: "rabbit-default-user",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: defaultUserSecretName(cr),
+						},
+					},
+				},
 			},
 		},
 	}
 
+	if cr.Spec.RabbitmqSSL.Enabled {
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, tlsVolume(cr))
+	}
+
 	PVCTemplate := corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:       "rabbit-data",
-			Finalizers: cr.ObjectMeta.Finalizers,
+			Name: dataVolumeName,
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
@@ -569,8 +747,11 @@ func newStatefulSet(cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) *v1.Sta
 			},
 			Template:             podTemplate,
 			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{PVCTemplate},
+			// all pods start together, node waiting for its peers after full stop doesn't block the others
+			PodManagementPolicy: v1.ParallelPodManagement,
 			UpdateStrategy: v1.StatefulSetUpdateStrategy{
-				Type: v1.RollingUpdateStatefulSetStrategyType,
+				// pods are restarted by operator, see reconcileRollout
+				Type: v1.OnDeleteStatefulSetStrategyType,
 			},
 		},
 	}
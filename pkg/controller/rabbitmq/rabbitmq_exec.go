@@ -0,0 +1,76 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// rabbitmqContainerName is the container running rabbitmq CLI tools
+const rabbitmqContainerName = "rabbitmq"
+
+// podExecutor runs commands in pod containers, like kubectl exec
+type podExecutor interface {
+	Exec(ctx context.Context, pod *corev1.Pod, container string, command ...string) (string, error)
+}
+
+type remotePodExecutor struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+func newPodExecutor(config *rest.Config, clientset kubernetes.Interface) podExecutor {
+	return &remotePodExecutor{config: config, clientset: clientset}
+}
+
+// Exec returns stdout of command, error contains stderr if command failed
+func (e *remotePodExecutor) Exec(ctx context.Context, pod *corev1.Pod, container string, command ...string) (string, error) {
+	request := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.config, "POST", request.URL())
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		// stream can't be cancelled, it ends when command exits or connection is closed
+		done <- executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		return "", fmt.Errorf("%s in pod %s: %v", strings.Join(command, " "), pod.Name, ctx.Err())
+	}
+	if err != nil {
+		return stdout.String(), fmt.Errorf("%s in pod %s: %v: %s", strings.Join(command, " "), pod.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// execInPod runs command in rabbitmq container of pod
+func (r *ReconcileRabbitmq) execInPod(ctx context.Context, pod *corev1.Pod, command ...string) (string, error) {
+	if r.podExecutor == nil {
+		return "", fmt.Errorf("pod exec is not configured")
+	}
+	return r.podExecutor.Exec(ctx, pod, rabbitmqContainerName, command...)
+}
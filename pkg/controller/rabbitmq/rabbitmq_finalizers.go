@@ -2,74 +2,159 @@ package rabbitmq
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
 	corev1 "k8s.io/api/core/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// RabbitmqPVCFinalizer keeps Rabbitmq until its data PVCs are handled according to deletionPolicy
 const RabbitmqPVCFinalizer = "rabbitmq.improvado.io/pvc"
 
-func (r *ReconcileRabbitmq) deleteDependentResoucePVC(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) (reconcile.Result, error) {
-	reqLogger.Info("Deleting PVC")
-	// Finalizer PVC: remove finalizer from CR
-	if containsString(cr.ObjectMeta.Finalizers, "PVC") {
-		// get dependent PVCs
-		foundDependentPVCs := &corev1.PersistentVolumeClaimList{}
+// snapshotRequeue snapshots are polled until they are ready to use
+const snapshotRequeue = 10 * time.Second
 
-		err := r.client.List(context.TODO(), client.InNamespace(cr.Namespace).MatchingLabels(mergeMaps(returnLabels(cr))), foundDependentPVCs)
-		if err != nil {
-			reqLogger.Info("Listing dependent PVCs error", "Namespace", cr.Namespace, ".Name", cr.Name)
-			return reconcile.Result{}, err
-		}
+// volumeSnapshotKind CSI snapshot API, types aren't vendored so unstructured objects are used
+var volumeSnapshotKind = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
 
-		for _, pvc := range foundDependentPVCs.Items {
-			// delete dependent PVCs
-			if err := r.client.Delete(context.Background(), &pvc); err != nil {
-				reqLogger.Info("Deleting PVC failed")
-				return reconcile.Result{}, err
+// volumeSnapshotName is unique for every Rabbitmq object, so snapshots of a recreated cluster with the same name are kept
+func volumeSnapshotName(cr *rabbitmqv1.Rabbitmq, pvc corev1.PersistentVolumeClaim) string {
+	uid := string(cr.UID)
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	return pvc.Name + "-" + uid
+}
+
+// snapshotVolumes creates VolumeSnapshot of every PVC and returns true when all of them are ready to use
+func (r *ReconcileRabbitmq) snapshotVolumes(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, pvcs []corev1.PersistentVolumeClaim) (bool, error) {
+	ready := true
+	for _, pvc := range pvcs {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotKind)
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: volumeSnapshotName(cr, pvc), Namespace: pvc.Namespace}, snapshot)
+		if err != nil && apierrors.IsNotFound(err) {
+			reqLogger.Info("Creating VolumeSnapshot", "PVC.Name", pvc.Name, "VolumeSnapshot.Name", volumeSnapshotName(cr, pvc))
+			snapshot.SetName(volumeSnapshotName(cr, pvc))
+			snapshot.SetNamespace(pvc.Namespace)
+			// no owner reference, snapshots outlive Rabbitmq
+			snapshot.SetLabels(returnLabels(cr))
+			snapshotSpec := map[string]interface{}{
+				"source": map[string]interface{}{"persistentVolumeClaimName": pvc.Name},
+			}
+			if cr.Spec.RabbitmqVolumeSnapshotClass != "" {
+				snapshotSpec["volumeSnapshotClassName"] = cr.Spec.RabbitmqVolumeSnapshotClass
+			}
+			if err := unstructured.SetNestedField(snapshot.Object, snapshotSpec, "spec"); err != nil {
+				return false, err
 			}
+			if err := r.client.Create(context.TODO(), snapshot); err != nil {
+				return false, err
+			}
+			ready = false
+			continue
+		} else if err != nil {
+			return false, err
+		}
+
+		if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+			return false, fmt.Errorf("VolumeSnapshot %s failed: %s", snapshot.GetName(), message)
+		}
+		if readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); !readyToUse {
+			reqLogger.Info("VolumeSnapshot is not ready", "VolumeSnapshot.Name", snapshot.GetName())
+			ready = false
 		}
 	}
-	return reconcile.Result{}, nil
+	return ready, nil
 }
 
-func (r *ReconcileRabbitmq) reconcileFinalizers(reqLogger logr.Logger, instance *rabbitmqv1.Rabbitmq) (reconcile.Result, error) {
-	reqLogger.Info("Processing finalizers", "Namespace", instance.Namespace, ".Name", instance.Name)
-	// Define finalizer strings to prevent deletion of CR before dependent resources deletion
-	finalizersList := []string{RabbitmqPVCFinalizer}
-
-	// Check CR is being deleted or not
-	if instance.ObjectMeta.DeletionTimestamp.IsZero() {
-		// Its a new CR, add finalizers from list
-		instance.ObjectMeta.Finalizers = finalizersList
-		if err := r.client.Update(context.Background(), instance); err != nil {
+// finalizeRabbitmq applies deletionPolicy to data PVCs and removes finalizer from Rabbitmq being deleted.
+// Vendored StatefulSet API has no persistentVolumeClaimRetentionPolicy, so PVCs are deleted here
+func (r *ReconcileRabbitmq) finalizeRabbitmq(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) (reconcile.Result, error) {
+	if !containsString(cr.ObjectMeta.Finalizers, RabbitmqPVCFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	reqLogger.Info("Finalizing Rabbitmq", "DeletionPolicy", cr.Spec.RabbitmqDeletionPolicy)
+
+	// pods are deleted with Rabbitmq, they must skip quorum checks of preStop hook first
+	ctx, cancel := context.WithTimeout(context.Background(), managementSyncTimeout)
+	defer cancel()
+	pending, err := r.skipPreStopChecks(ctx, reqLogger, cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if pending != "" {
+		reqLogger.Info("Waiting for pod to see preStop annotation", "Pod.Name", pending)
+		return reconcile.Result{RequeueAfter: podInfoRequeue}, nil
+	}
+
+	// copies in other namespaces aren't garbage collected
+	if err := r.deleteBindingCopies(reqLogger, cr); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	pvcs, err := r.listDataVolumes(cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if cr.Spec.RabbitmqDeletionPolicy == rabbitmqv1.RabbitmqDeletionPolicySnapshotThenDelete {
+		ready, err := r.snapshotVolumes(reqLogger, cr, pvcs)
+		if err != nil {
 			return reconcile.Result{}, err
 		}
+		if !ready {
+			return reconcile.Result{RequeueAfter: snapshotRequeue}, nil
+		}
+	}
 
-	} else {
-		// The object is being deleted, removing dependencies and finalizers after it
-
-		// Finalizer "PVC": check to remove dependent PVCs
-		if instance.Spec.RabbitmqPurgePVC {
-			_, err := r.deleteDependentResoucePVC(reqLogger, instance)
-			if err != nil {
-				reqLogger.Info("PVC deletion error", "Namespace", instance.Namespace, ".Name", instance.Name)
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		// PVC template used to copy Rabbitmq finalizers, nothing removes them from PVCs created before
+		if containsString(pvc.ObjectMeta.Finalizers, RabbitmqPVCFinalizer) {
+			pvc.ObjectMeta.Finalizers = removeString(pvc.ObjectMeta.Finalizers, RabbitmqPVCFinalizer)
+			if err := r.client.Update(context.TODO(), pvc); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
 
-		// Finalizer "PVC": remove "PVC" finalizer from CR
-		instance.ObjectMeta.Finalizers = removeString(instance.ObjectMeta.Finalizers, RabbitmqPVCFinalizer)
-		if err := r.client.Update(context.Background(), instance); err != nil {
-			reqLogger.Info("Removing PVC finalizer failed")
+		if cr.Spec.RabbitmqDeletionPolicy == rabbitmqv1.RabbitmqDeletionPolicyRetain {
+			continue
+		}
+		reqLogger.Info("Deleting PVC", "PVC.Namespace", pvc.Namespace, "PVC.Name", pvc.Name)
+		if err := r.client.Delete(context.TODO(), pvc); err != nil && !apierrors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
+	}
 
-		// Our finalizer has finished, so the reconciler can do nothing.
+	cr.ObjectMeta.Finalizers = removeString(cr.ObjectMeta.Finalizers, RabbitmqPVCFinalizer)
+	if err := r.client.Update(context.TODO(), cr); err != nil {
+		reqLogger.Info("Removing PVC finalizer failed")
+		return reconcile.Result{}, err
 	}
+	return reconcile.Result{}, nil
+}
 
+// reconcileFinalizers adds PVC finalizer to Rabbitmq, other finalizers are kept
+func (r *ReconcileRabbitmq) reconcileFinalizers(reqLogger logr.Logger, instance *rabbitmqv1.Rabbitmq) (reconcile.Result, error) {
+	if containsString(instance.ObjectMeta.Finalizers, RabbitmqPVCFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	reqLogger.Info("Adding PVC finalizer", "Namespace", instance.Namespace, ".Name", instance.Name)
+
+	// update a copy, so the status collected during reconcile is not overwritten by the response
+	instanceCopy := instance.DeepCopy()
+	instanceCopy.ObjectMeta.Finalizers = append(instanceCopy.ObjectMeta.Finalizers, RabbitmqPVCFinalizer)
+	if err := r.client.Update(context.TODO(), instanceCopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	instance.ObjectMeta = instanceCopy.ObjectMeta
 	return reconcile.Result{}, nil
 }
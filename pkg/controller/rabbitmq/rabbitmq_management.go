@@ -0,0 +1,102 @@
+package rabbitmq
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// managementSyncTimeout limits all management API requests of one sync
+	managementSyncTimeout = 30 * time.Second
+
+	// managementResyncPeriod policies and users are synced again after it, so changes made by hand are reverted
+	managementResyncPeriod = 5 * time.Minute
+
+	// backoff of failed sync grows from min to max
+	managementBackoffMin = 5 * time.Second
+	managementBackoffMax = 5 * time.Minute
+)
+
+// managementBackoff counts failed syncs in a row for every Rabbitmq
+type managementBackoff struct {
+	mu       sync.Mutex
+	failures map[types.NamespacedName]uint
+}
+
+// next registers failure and returns delay before next attempt
+func (b *managementBackoff) next(key types.NamespacedName) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures == nil {
+		b.failures = map[types.NamespacedName]uint{}
+	}
+	delay := managementBackoffMin
+	for i := uint(0); i < b.failures[key] && delay < managementBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > managementBackoffMax {
+		delay = managementBackoffMax
+	}
+	b.failures[key]++
+	return delay
+}
+
+func (b *managementBackoff) reset(key types.NamespacedName) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, key)
+}
+
+// reconcileManagement syncs cluster status, policies and users through management API after statefulset has ready pods.
+// Errors of every step are written to status conditions, result requeues with backoff on failure and for periodic resync
+func (r *ReconcileRabbitmq) reconcileManagement(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces, statefulset *v1.StatefulSet) reconcile.Result {
+	key := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+
+	// statefulset watch starts reconcile when pods become ready
+	if statefulset.Status.ReadyReplicas == 0 {
+		reqLogger.Info("Management sync is waiting for ready pods")
+		for _, conditionType := range []rabbitmqv1.RabbitmqConditionType{rabbitmqv1.RabbitmqConditionManagementAPIReachable, rabbitmqv1.RabbitmqConditionPoliciesSynced, rabbitmqv1.RabbitmqConditionUsersSynced} {
+			setStatusCondition(&cr.Status.Conditions, conditionType, corev1.ConditionUnknown, "WaitingForReadyPods", "")
+		}
+		return reconcile.Result{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), managementSyncTimeout)
+	defer cancel()
+
+	// read versions and nodes, it also checks that API is reachable
+	reqLogger.Info("Reading cluster status")
+	if err := r.reconcileClusterStatus(ctx, reqLogger, cr, secretNames); err != nil {
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionPoliciesSynced, corev1.ConditionUnknown, "ManagementAPIUnreachable", "")
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionUsersSynced, corev1.ConditionUnknown, "ManagementAPIUnreachable", "")
+		delay := r.managementBackoff.next(key)
+		reqLogger.Info("Management API unreachable, retrying", "Error", err.Error(), "RequeueAfter", delay.String())
+		return reconcile.Result{RequeueAfter: delay}
+	}
+
+	reqLogger.Info("Setting up policies")
+	policiesErr := r.setPolicies(ctx, reqLogger, cr, secretNames)
+	setStatusConditionFromError(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionPoliciesSynced, policiesErr, "SyncFailed")
+
+	reqLogger.Info("Setting up additional users")
+	usersErr := r.syncUsersCredentials(ctx, reqLogger, cr, secretNames)
+	setStatusConditionFromError(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionUsersSynced, usersErr, "SyncFailed")
+
+	if policiesErr != nil || usersErr != nil {
+		delay := r.managementBackoff.next(key)
+		reqLogger.Info("Management sync failed, retrying", "RequeueAfter", delay.String())
+		return reconcile.Result{RequeueAfter: delay}
+	}
+
+	r.managementBackoff.reset(key)
+	return reconcile.Result{RequeueAfter: managementResyncPeriod}
+}
@@ -0,0 +1,283 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeClient serves secrets and pods and records updates, other objects are kept by kind and name
+type fakeClient struct {
+	client.Client
+	secrets       map[string]*corev1.Secret
+	objects       map[string]runtime.Object
+	pods          []corev1.Pod
+	statusUpdates int
+	updatedPods   []corev1.Pod
+	deletedPods   []string
+	created       []string
+}
+
+// fakeObjectKey is kind and name of object, unstructured objects have kind set
+func fakeObjectKey(obj runtime.Object, key client.ObjectKey) string {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		kind = reflect.TypeOf(obj).Elem().Name()
+	}
+	return kind + "/" + key.Namespace + "/" + key.Name
+}
+
+func (c *fakeClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if secret, ok := obj.(*corev1.Secret); ok {
+		found, exists := c.secrets[key.Namespace+"/"+key.Name]
+		if !exists {
+			return apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+		}
+		found.DeepCopyInto(secret)
+		return nil
+	}
+	found, exists := c.objects[fakeObjectKey(obj, key)]
+	if !exists {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: fakeObjectKey(obj, key)}, key.Name)
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(found.DeepCopyObject()).Elem())
+	return nil
+}
+
+func (c *fakeClient) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	switch list := list.(type) {
+	case *corev1.PodList:
+		list.Items = append([]corev1.Pod{}, c.pods...)
+	case *rabbitmqv1.RabbitmqUserList:
+		// no RabbitmqUser resources
+	default:
+		return errors.New("only pods and users are listed")
+	}
+	return nil
+}
+
+func (c *fakeClient) Create(ctx context.Context, obj runtime.Object) error {
+	meta, err := apimeta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	key := client.ObjectKey{Namespace: meta.GetNamespace(), Name: meta.GetName()}
+	c.created = append(c.created, fakeObjectKey(obj, key))
+	c.store(obj, key)
+	return nil
+}
+
+func (c *fakeClient) Update(ctx context.Context, obj runtime.Object) error {
+	if pod, ok := obj.(*corev1.Pod); ok {
+		c.updatedPods = append(c.updatedPods, *pod)
+		return nil
+	}
+	meta, err := apimeta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	c.store(obj, client.ObjectKey{Namespace: meta.GetNamespace(), Name: meta.GetName()})
+	return nil
+}
+
+func (c *fakeClient) store(obj runtime.Object, key client.ObjectKey) {
+	if secret, ok := obj.(*corev1.Secret); ok {
+		if c.secrets == nil {
+			c.secrets = map[string]*corev1.Secret{}
+		}
+		c.secrets[key.Namespace+"/"+key.Name] = secret.DeepCopy()
+		return
+	}
+	if c.objects == nil {
+		c.objects = map[string]runtime.Object{}
+	}
+	c.objects[fakeObjectKey(obj, key)] = obj.DeepCopyObject()
+}
+
+func (c *fakeClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	if pod, ok := obj.(*corev1.Pod); ok {
+		c.deletedPods = append(c.deletedPods, pod.Name)
+	}
+	return nil
+}
+
+func (c *fakeClient) Status() client.StatusWriter {
+	return &fakeStatusWriter{client: c}
+}
+
+type fakeStatusWriter struct {
+	client *fakeClient
+}
+
+func (w *fakeStatusWriter) Update(ctx context.Context, obj runtime.Object) error {
+	w.client.statusUpdates++
+	return nil
+}
+
+// fakeAPIClient is management API of a cluster, methods not used by tests panic
+type fakeAPIClient struct {
+	rabbitmqclient.Client
+	overview    rabbitmqclient.Overview
+	overviewErr error
+	nodes       []rabbitmqclient.Node
+
+	policiesErr      error
+	operatorPolicies []rabbitmqclient.Policy
+	putOperator      []string
+	users            []rabbitmqclient.User
+	deletedUsers     []string
+}
+
+func (c *fakeAPIClient) Overview(ctx context.Context) (rabbitmqclient.Overview, error) {
+	return c.overview, c.overviewErr
+}
+
+func (c *fakeAPIClient) ListNodes(ctx context.Context) ([]rabbitmqclient.Node, error) {
+	return c.nodes, nil
+}
+
+func (c *fakeAPIClient) ListPolicies(ctx context.Context) ([]rabbitmqclient.Policy, error) {
+	return nil, c.policiesErr
+}
+
+func (c *fakeAPIClient) ListOperatorPolicies(ctx context.Context) ([]rabbitmqclient.Policy, error) {
+	return c.operatorPolicies, nil
+}
+
+func (c *fakeAPIClient) PutOperatorPolicy(ctx context.Context, policy rabbitmqclient.Policy) error {
+	c.putOperator = append(c.putOperator, policy.Vhost+"/"+policy.Name)
+	return nil
+}
+
+func (c *fakeAPIClient) ListUsers(ctx context.Context) ([]rabbitmqclient.User, error) {
+	return c.users, nil
+}
+
+func (c *fakeAPIClient) DeleteUser(ctx context.Context, name string) error {
+	c.deletedUsers = append(c.deletedUsers, name)
+	return nil
+}
+
+func newManagementTest(apiClient *fakeAPIClient) (*ReconcileRabbitmq, *fakeClient, *rabbitmqv1.Rabbitmq, *[]rabbitmqclient.Options) {
+	cr := &rabbitmqv1.Rabbitmq{
+		ObjectMeta: metav1.ObjectMeta{Name: "rabbit", Namespace: "queues"},
+		Spec:       rabbitmqv1.RabbitmqSpec{RabbitmqReplicas: 3},
+	}
+	k8sClient := &fakeClient{secrets: map[string]*corev1.Secret{
+		"queues/rabbit-service-account": {Data: map[string][]byte{"username": []byte("operator"), "password": []byte("secret")}},
+	}}
+	var options []rabbitmqclient.Options
+	r := &ReconcileRabbitmq{
+		client: k8sClient,
+		newAPIClient: func(o rabbitmqclient.Options) (rabbitmqclient.Client, error) {
+			options = append(options, o)
+			return apiClient, nil
+		},
+	}
+	return r, k8sClient, cr, &options
+}
+
+func conditionStatus(cr *rabbitmqv1.Rabbitmq, conditionType rabbitmqv1.RabbitmqConditionType) (corev1.ConditionStatus, string) {
+	for _, condition := range cr.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status, condition.Reason
+		}
+	}
+	return "", ""
+}
+
+func TestReconcileClusterStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		apiClient     *fakeAPIClient
+		wantErr       bool
+		wantReachable corev1.ConditionStatus
+		wantFormed    corev1.ConditionStatus
+		wantReason    string
+	}{
+		{
+			name: "all nodes running",
+			apiClient: &fakeAPIClient{
+				overview: rabbitmqclient.Overview{RabbitmqVersion: "3.12.4", ErlangVersion: "26.0"},
+				nodes:    []rabbitmqclient.Node{{Name: "rabbit@0", Running: true}, {Name: "rabbit@1", Running: true}, {Name: "rabbit@2", Running: true}},
+			},
+			wantReachable: corev1.ConditionTrue,
+			wantFormed:    corev1.ConditionTrue,
+			wantReason:    "AllNodesRunning",
+		},
+		{
+			name: "network partition",
+			apiClient: &fakeAPIClient{
+				nodes: []rabbitmqclient.Node{{Name: "rabbit@0", Running: true, Partitions: []string{"rabbit@1"}}, {Name: "rabbit@1", Running: true}, {Name: "rabbit@2", Running: true}},
+			},
+			wantReachable: corev1.ConditionTrue,
+			wantFormed:    corev1.ConditionFalse,
+			wantReason:    "NetworkPartition",
+		},
+		{
+			name:          "wrong credentials",
+			apiClient:     &fakeAPIClient{overviewErr: &rabbitmqclient.Error{StatusCode: http.StatusUnauthorized}},
+			wantErr:       true,
+			wantReachable: corev1.ConditionFalse,
+			wantFormed:    corev1.ConditionUnknown,
+			wantReason:    "ManagementAPIUnreachable",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, _, cr, options := newManagementTest(test.apiClient)
+
+			err := r.reconcileClusterStatus(context.Background(), log, cr, getSecretNames(cr))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v, want error %v", err, test.wantErr)
+			}
+			if len(*options) != 1 || (*options)[0].Username != "operator" || (*options)[0].Password != "secret" || (*options)[0].BaseURL != "http://rabbit-api.queues:15672" {
+				t.Errorf("client options = %+v", *options)
+			}
+			if status, _ := conditionStatus(cr, rabbitmqv1.RabbitmqConditionManagementAPIReachable); status != test.wantReachable {
+				t.Errorf("ManagementAPIReachable = %s, want %s", status, test.wantReachable)
+			}
+			if status, reason := conditionStatus(cr, rabbitmqv1.RabbitmqConditionClusterFormed); status != test.wantFormed || reason != test.wantReason {
+				t.Errorf("ClusterFormed = %s %s, want %s %s", status, reason, test.wantFormed, test.wantReason)
+			}
+			if !test.wantErr && cr.Status.RabbitmqVersion != test.apiClient.overview.RabbitmqVersion {
+				t.Errorf("version = %s", cr.Status.RabbitmqVersion)
+			}
+		})
+	}
+}
+
+func TestSetPoliciesSyncsOperatorPoliciesWhenPoliciesFail(t *testing.T) {
+	apiClient := &fakeAPIClient{policiesErr: errors.New("policies unavailable")}
+	r, k8sClient, cr, _ := newManagementTest(apiClient)
+	cr.Status.PoliciesTracked = true
+	cr.Spec.RabbitmqOperatorPolicies = []rabbitmqv1.RabbitmqPolicy{{
+		Name:       "limits",
+		Pattern:    ".*",
+		Definition: rabbitmqv1.RabbitmqPolicyDefinition{"max-length": 1000},
+	}}
+
+	err := r.setPolicies(context.Background(), log, cr, getSecretNames(cr))
+	if err == nil {
+		t.Fatal("policies error was not returned")
+	}
+	if len(apiClient.putOperator) != 1 || apiClient.putOperator[0] != "//limits" {
+		t.Errorf("operator policies written = %v", apiClient.putOperator)
+	}
+	if k8sClient.statusUpdates != 1 || len(cr.Status.OperatorPolicies) != 1 {
+		t.Errorf("operator policies recorded %d times: %v", k8sClient.statusUpdates, cr.Status.OperatorPolicies)
+	}
+}
@@ -2,118 +2,240 @@ package rabbitmq
 
 import (
 	"context"
-	"net"
-	"time"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/getsentry/raven-go"
 	"github.com/go-logr/logr"
 	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
-// setPolicies run as go routine
+// setPolicies syncs policies from spec, vhost and name identify policy
 func (r *ReconcileRabbitmq) setPolicies(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) error {
 
 	// get service account credentials
-	var serviceAccount basicAuthCredentials
-
-	username, err := r.getSecretData(reqLogger, cr.Namespace, secretNames.ServiceAccount, "username")
-	serviceAccount.username = username
+	apiClient, serviceAccount, err := r.getAPIClient(reqLogger, cr, secretNames)
 	if err != nil {
-		reqLogger.Info("Users: auth username not found")
+		reqLogger.Info("Policies: service account not found")
 		raven.CaptureErrorAndWait(err, nil)
 		return err
 	}
 
-	password, err := r.getSecretData(reqLogger, cr.Namespace, secretNames.ServiceAccount, "password")
-	serviceAccount.password = password
+	reqLogger.Info("Policies: Using API service: "+r.apiServiceAddress(cr), "username", serviceAccount.username)
+
+	// operator policies are synced even if policies failed, errors of both are returned
+	policiesErr := r.syncSpecPolicies(ctx, reqLogger, cr, apiClient)
+	operatorPoliciesErr := r.syncOperatorPolicies(ctx, reqLogger, cr, apiClient)
+	err = utilerrors.NewAggregate([]error{policiesErr, operatorPoliciesErr})
 	if err != nil {
-		reqLogger.Info("Users: auth password not found")
 		raven.CaptureErrorAndWait(err, nil)
-		return err
 	}
+	return err
+}
 
-	// wait http connection to api port
-	timeout := time.Duration(5 * time.Second)
+// syncSpecPolicies syncs policies and records owned ones in status.policies
+func (r *ReconcileRabbitmq) syncSpecPolicies(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client) error {
+	// get exiting policies
+	reqLogger.Info("Reading exiting policies")
 
-	_, err = net.DialTimeout("tcp", r.apiServiceHostname(cr), timeout)
+	policiesRabbit, err := apiClient.ListPolicies(ctx)
 	if err != nil {
-		reqLogger.Info("Rabbitmq API service failed", "Service name", r.apiServiceHostname(cr), "Error", err.Error())
-		raven.CaptureErrorAndWait(err, nil)
+		reqLogger.Info("Error while receiving policies list", "Error", err.Error())
 		return err
 	}
-	reqLogger.Info("Policies: Using API service: "+r.apiServiceAddress(cr), "username", serviceAccount.username)
 
-	var policiesCR []rabbitmqv1.RabbitmqPolicy
-
-	// get exiting policies
-	reqLogger.Info("Reading exiting policies")
+	// status is written before policies, so policies written by operator are recorded even if reconcile fails later
+	owned, err := syncPolicies(reqLogger, policiesWithDefaults(cr, cr.Spec.RabbitmqPolicies, "all"), policiesFromAPI(policiesRabbit), cr.Status.Policies, !cr.Status.PoliciesTracked,
+		func(owned []rabbitmqv1.RabbitmqPolicyReference) error {
+			cr.Status.Policies = owned
+			cr.Status.PoliciesTracked = true
+			return r.client.Status().Update(ctx, cr)
+		},
+		func(policy rabbitmqv1.RabbitmqPolicy) error {
+			return apiClient.PutPolicy(ctx, policyToAPI(policy))
+		},
+		func(policy rabbitmqv1.RabbitmqPolicyReference) error {
+			return apiClient.DeletePolicy(ctx, policy.Vhost, policy.Name)
+		})
+	cr.Status.Policies = owned
+	return err
+}
 
-	policiesRabbit, err := r.apiPolicyList(reqLogger, cr, serviceAccount)
+// syncOperatorPolicies syncs operator policies the same way and records owned ones in status.operatorPolicies
+func (r *ReconcileRabbitmq) syncOperatorPolicies(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client) error {
+	operatorPoliciesRabbit, err := apiClient.ListOperatorPolicies(ctx)
 	if err != nil {
-		reqLogger.Info("Error while receiving policies list", "Error", err.Error())
-		raven.CaptureErrorAndWait(err, nil)
+		reqLogger.Info("Error while receiving operator policies list", "Error", err.Error())
 		return err
 	}
 
-	// get policies from CR
-	reqLogger.Info("Reading policies from CRD")
+	owned, err := syncPolicies(reqLogger, policiesWithDefaults(cr, cr.Spec.RabbitmqOperatorPolicies, "queues"), policiesFromAPI(operatorPoliciesRabbit), cr.Status.OperatorPolicies, false,
+		func(owned []rabbitmqv1.RabbitmqPolicyReference) error {
+			cr.Status.OperatorPolicies = owned
+			return r.client.Status().Update(ctx, cr)
+		},
+		func(policy rabbitmqv1.RabbitmqPolicy) error {
+			return apiClient.PutOperatorPolicy(ctx, policyToAPI(policy))
+		},
+		func(policy rabbitmqv1.RabbitmqPolicyReference) error {
+			return apiClient.DeleteOperatorPolicy(ctx, policy.Vhost, policy.Name)
+		})
+	cr.Status.OperatorPolicies = owned
+	return err
+}
 
-	// set default vhost for all policies
-	policiesDefaultVhost := "%2f"
-	if cr.Spec.RabbitmqVhost != "" {
-		policiesDefaultVhost = cr.Spec.RabbitmqVhost
+func policiesFromAPI(policies []rabbitmqclient.Policy) []rabbitmqv1.RabbitmqPolicy {
+	var result []rabbitmqv1.RabbitmqPolicy
+	for _, policy := range policies {
+		result = append(result, rabbitmqv1.RabbitmqPolicy{
+			Vhost:      policy.Vhost,
+			Name:       policy.Name,
+			Pattern:    policy.Pattern,
+			Definition: policy.Definition,
+			Priority:   policy.Priority,
+			ApplyTo:    policy.ApplyTo,
+		})
 	}
+	return result
+}
 
-	// detect vhost to use
-	for _, policy := range cr.Spec.RabbitmqPolicies {
-		// detect vhost to use
-		policyVhost := ""
-		if policy.Vhost != "" {
-			policyVhost = policy.Vhost
-		} else {
-			policyVhost = policiesDefaultVhost
+func policyToAPI(policy rabbitmqv1.RabbitmqPolicy) rabbitmqclient.Policy {
+	return rabbitmqclient.Policy{
+		Vhost:      policy.Vhost,
+		Name:       policy.Name,
+		Pattern:    policy.Pattern,
+		Definition: policy.Definition,
+		Priority:   policy.Priority,
+		ApplyTo:    policy.ApplyTo,
+	}
+}
+
+// policiesWithDefaults returns copy of policies with defaults rabbitmq would set,
+// policies without vhost are applied to default_vhost or "/" if it is not set, "%2f" is "/"
+func policiesWithDefaults(cr *rabbitmqv1.Rabbitmq, policies []rabbitmqv1.RabbitmqPolicy, defaultApplyTo string) []rabbitmqv1.RabbitmqPolicy {
+	var policiesCR []rabbitmqv1.RabbitmqPolicy
+	for _, policy := range policies {
+		if policy.Vhost == "" {
+			policy.Vhost = cr.PolicyVhost()
 		}
+		// earlier versions put vhost into URL as is, so root vhost had to be written escaped
+		if policy.Vhost == "%2f" || policy.Vhost == "%2F" {
+			policy.Vhost = "/"
+		}
+		if policy.ApplyTo == "" {
+			policy.ApplyTo = defaultApplyTo
+		}
+		policiesCR = append(policiesCR, policy)
+	}
+	return policiesCR
+}
 
-		policy.Vhost = policyVhost
+// policyKey identifies policy, names are unique only in a vhost
+func policyKey(vhost string, name string) rabbitmqv1.RabbitmqPolicyReference {
+	return rabbitmqv1.RabbitmqPolicyReference{Vhost: vhost, Name: name}
+}
 
-		policiesCR = append(policiesCR, policy)
+func policiesEqual(a rabbitmqv1.RabbitmqPolicy, b rabbitmqv1.RabbitmqPolicy) bool {
+	return a.Pattern == b.Pattern && a.Priority == b.Priority && a.ApplyTo == b.ApplyTo && argumentsEqual(a.Definition, b.Definition)
+}
+
+// syncPolicies writes only changed policies and removes only policies owned by operator.
+// Policy existing in rabbitmq and not owned is taken over only with adopt, or with seed for clusters upgraded
+// from versions which did not record owned policies. Owned policies are passed to recordOwned before
+// they are written, so a policy is never written without being recorded. Returns owned policies
+func syncPolicies(reqLogger logr.Logger, policiesCR []rabbitmqv1.RabbitmqPolicy, policiesRabbit []rabbitmqv1.RabbitmqPolicy, ownedBefore []rabbitmqv1.RabbitmqPolicyReference, seed bool,
+	recordOwned func([]rabbitmqv1.RabbitmqPolicyReference) error, addPolicy func(rabbitmqv1.RabbitmqPolicy) error, removePolicy func(rabbitmqv1.RabbitmqPolicyReference) error) ([]rabbitmqv1.RabbitmqPolicyReference, error) {
 
+	existing := map[rabbitmqv1.RabbitmqPolicyReference]rabbitmqv1.RabbitmqPolicy{}
+	for _, policy := range policiesRabbit {
+		existing[policyKey(policy.Vhost, policy.Name)] = policy
 	}
 
-	// ok, now syncing
+	ownedBeforeSet := map[rabbitmqv1.RabbitmqPolicyReference]bool{}
+	for _, policy := range ownedBefore {
+		ownedBeforeSet[policyKey(policy.Vhost, policy.Name)] = true
+	}
 
-	// remove policies from rabbit
-	for _, policyRabbit := range policiesRabbit {
+	desired := map[rabbitmqv1.RabbitmqPolicyReference]bool{}
+	var accepted []rabbitmqv1.RabbitmqPolicy
+	var conflicts []string
 
-		// search
-		policyFound := false
-		for _, policyCR := range policiesCR {
-			if policyCR.Name == policyRabbit.Name {
-				policyFound = true
-			}
+	for _, policy := range policiesCR {
+		key := policyKey(policy.Vhost, policy.Name)
+		desired[key] = true
+
+		if _, found := existing[key]; found && !ownedBeforeSet[key] && !policy.Adopt && !seed {
+			conflicts = append(conflicts, policy.Vhost+"/"+policy.Name)
+			continue
 		}
+		accepted = append(accepted, policy)
+	}
 
-		if !policyFound {
-			reqLogger.Info("Removing " + policyRabbit.Name)
-			err = r.apiPolicyRemove(reqLogger, cr, serviceAccount, policyRabbit.Vhost, policyRabbit.Name)
-			if err != nil {
-				raven.CaptureErrorAndWait(err, nil)
-				return err
-			}
+	// record new policies together with owned ones before writing anything
+	recorded := append([]rabbitmqv1.RabbitmqPolicyReference{}, ownedBefore...)
+	for _, policy := range accepted {
+		if !ownedBeforeSet[policyKey(policy.Vhost, policy.Name)] {
+			recorded = append(recorded, rabbitmqv1.RabbitmqPolicyReference{Vhost: policy.Vhost, Name: policy.Name})
+		}
+	}
+	if len(recorded) != len(ownedBefore) || seed {
+		sortPolicyReferences(recorded)
+		if err := recordOwned(recorded); err != nil {
+			reqLogger.Info("Error recording owned policies", "Error", err.Error())
+			return ownedBefore, err
 		}
+	}
+
+	var owned []rabbitmqv1.RabbitmqPolicyReference
+	var syncErr error
 
+	for _, policy := range accepted {
+		if policyRabbit, found := existing[policyKey(policy.Vhost, policy.Name)]; !found || !policiesEqual(policy, policyRabbit) {
+			reqLogger.Info("Adding policy " + policy.Name + " to vhost " + policy.Vhost)
+			if err := addPolicy(policy); err != nil {
+				reqLogger.Info("Error adding policy "+policy.Name+" to vhost "+policy.Vhost, "Error", err)
+				syncErr = err
+			}
+		}
+		// still owned when writing failed, it is recorded and may have been written
+		owned = append(owned, rabbitmqv1.RabbitmqPolicyReference{Vhost: policy.Vhost, Name: policy.Name})
 	}
 
-	// add to rabbit from CR
-	for _, policyCR := range policiesCR {
-		reqLogger.Info("Adding policy " + policyCR.Name + " to vhost " + policyCR.Vhost)
-		err = r.apiPolicyAdd(reqLogger, cr, serviceAccount, policyCR.Vhost, policyCR)
-		if err != nil {
-			reqLogger.Info("Error adding policy "+policyCR.Name+" to vhost "+policyCR.Vhost, "Error", err)
-			raven.CaptureErrorAndWait(err, nil)
-			return err
+	// remove policies created by operator and deleted from spec, policies created by hand are kept
+	for _, policy := range ownedBefore {
+		key := policyKey(policy.Vhost, policy.Name)
+		if desired[key] {
+			continue
+		}
+		if _, found := existing[key]; found {
+			reqLogger.Info("Removing policy " + policy.Name + " from vhost " + policy.Vhost)
+			if err := removePolicy(policy); err != nil {
+				// still owned, removal is retried on next reconcile
+				owned = append(owned, policy)
+				syncErr = err
+			}
 		}
 	}
 
-	return nil
+	sortPolicyReferences(owned)
+
+	if syncErr != nil {
+		return owned, syncErr
+	}
+	if len(conflicts) > 0 {
+		return owned, fmt.Errorf("policies %s exist and are not managed by operator, set adopt to take them over", strings.Join(conflicts, ", "))
+	}
+	return owned, nil
+}
+
+func sortPolicyReferences(policies []rabbitmqv1.RabbitmqPolicyReference) {
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Vhost != policies[j].Vhost {
+			return policies[i].Vhost < policies[j].Vhost
+		}
+		return policies[i].Name < policies[j].Name
+	})
 }
@@ -0,0 +1,116 @@
+package rabbitmq
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+)
+
+func testPolicy(name string, priority int64) rabbitmqv1.RabbitmqPolicy {
+	return rabbitmqv1.RabbitmqPolicy{
+		Vhost:      "/",
+		Name:       name,
+		Pattern:    ".*",
+		Definition: rabbitmqv1.RabbitmqPolicyDefinition{"ha-mode": "all"},
+		Priority:   priority,
+		ApplyTo:    "all",
+	}
+}
+
+func TestSyncPolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		policiesCR  []rabbitmqv1.RabbitmqPolicy
+		existing    []rabbitmqv1.RabbitmqPolicy
+		ownedBefore []rabbitmqv1.RabbitmqPolicyReference
+		seed        bool
+		recordErr   error
+		wantAdded   []string
+		wantRemoved []string
+		wantOwned   []rabbitmqv1.RabbitmqPolicyReference
+		wantErr     bool
+	}{
+		{
+			name:       "new policy is recorded and written",
+			policiesCR: []rabbitmqv1.RabbitmqPolicy{testPolicy("ha", 0)},
+			wantAdded:  []string{"ha"},
+			wantOwned:  []rabbitmqv1.RabbitmqPolicyReference{{Vhost: "/", Name: "ha"}},
+		},
+		{
+			name:       "identical policy created by hand is not adopted",
+			policiesCR: []rabbitmqv1.RabbitmqPolicy{testPolicy("ha", 0)},
+			existing:   []rabbitmqv1.RabbitmqPolicy{testPolicy("ha", 0)},
+			wantErr:    true,
+		},
+		{
+			name:       "policies from spec are taken over on upgrade",
+			policiesCR: []rabbitmqv1.RabbitmqPolicy{testPolicy("ha", 1)},
+			existing:   []rabbitmqv1.RabbitmqPolicy{testPolicy("ha", 0), testPolicy("manual", 0)},
+			seed:       true,
+			wantAdded:  []string{"ha"},
+			wantOwned:  []rabbitmqv1.RabbitmqPolicyReference{{Vhost: "/", Name: "ha"}},
+		},
+		{
+			name:        "owned policy removed from spec is deleted",
+			existing:    []rabbitmqv1.RabbitmqPolicy{testPolicy("old", 0), testPolicy("manual", 0)},
+			ownedBefore: []rabbitmqv1.RabbitmqPolicyReference{{Vhost: "/", Name: "old"}},
+			wantRemoved: []string{"old"},
+		},
+		{
+			name:        "nothing is written when ownership is not recorded",
+			policiesCR:  []rabbitmqv1.RabbitmqPolicy{testPolicy("ha", 0)},
+			ownedBefore: []rabbitmqv1.RabbitmqPolicyReference{{Vhost: "/", Name: "old"}},
+			recordErr:   errors.New("conflict"),
+			wantOwned:   []rabbitmqv1.RabbitmqPolicyReference{{Vhost: "/", Name: "old"}},
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var added, removed []string
+			var recorded []rabbitmqv1.RabbitmqPolicyReference
+			owned, err := syncPolicies(log, test.policiesCR, test.existing, test.ownedBefore, test.seed,
+				func(owned []rabbitmqv1.RabbitmqPolicyReference) error {
+					recorded = owned
+					return test.recordErr
+				},
+				func(policy rabbitmqv1.RabbitmqPolicy) error {
+					// ownership must be recorded before policy is written
+					if !containsPolicyReference(recorded, policy) {
+						t.Errorf("policy %s written before it was recorded", policy.Name)
+					}
+					added = append(added, policy.Name)
+					return nil
+				},
+				func(policy rabbitmqv1.RabbitmqPolicyReference) error {
+					removed = append(removed, policy.Name)
+					return nil
+				})
+
+			if (err != nil) != test.wantErr {
+				t.Errorf("error = %v, want error %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(added, test.wantAdded) {
+				t.Errorf("added = %v, want %v", added, test.wantAdded)
+			}
+			if !reflect.DeepEqual(removed, test.wantRemoved) {
+				t.Errorf("removed = %v, want %v", removed, test.wantRemoved)
+			}
+			if !reflect.DeepEqual(owned, test.wantOwned) {
+				t.Errorf("owned = %v, want %v", owned, test.wantOwned)
+			}
+		})
+	}
+}
+
+func containsPolicyReference(policies []rabbitmqv1.RabbitmqPolicyReference, policy rabbitmqv1.RabbitmqPolicy) bool {
+	for _, reference := range policies {
+		if reference.Vhost == policy.Vhost && reference.Name == policy.Name {
+			return true
+		}
+	}
+	return false
+}
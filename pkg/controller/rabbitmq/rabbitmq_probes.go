@@ -0,0 +1,61 @@
+package rabbitmq
+
+import (
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// boot of an empty node
+	probeBaseDelaySeconds = 60
+	// node recovers queues and message store from data volume on boot, every GiB adds to boot time
+	probeDelaySecondsPerGiB = 6
+	probeMaxDelaySeconds    = 3600
+)
+
+// bootDelaySeconds returns time a node may need to boot with full data volume.
+// Vendored pod API has no startupProbe, liveness probe waits this long before the first check instead
+func bootDelaySeconds(cr *rabbitmqv1.Rabbitmq) int32 {
+	delay := int64(probeBaseDelaySeconds) + cr.Spec.RabbitmqVolumeSize.Value()/(1<<30)*probeDelaySecondsPerGiB
+	if delay > probeMaxDelaySeconds {
+		delay = probeMaxDelaySeconds
+	}
+	return int32(delay)
+}
+
+// readinessProbe keeps pod out of client services until AMQP listener is started, it is started after the node joined cluster.
+// AMQPS listener is checked when plaintext one is disabled
+func readinessProbe(cr *rabbitmqv1.Rabbitmq) *corev1.Probe {
+	if cr.Spec.RabbitmqReadinessProbe != nil {
+		return cr.Spec.RabbitmqReadinessProbe
+	}
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(amqpPort(cr))},
+		},
+		InitialDelaySeconds: 10,
+		TimeoutSeconds:      5,
+		PeriodSeconds:       10,
+		SuccessThreshold:    1,
+		FailureThreshold:    3,
+	}
+}
+
+// livenessProbe restarts container when erlang node doesn't respond.
+// ping succeeds when rabbit app is stopped, so nodes stopped by scale down aren't restarted
+func livenessProbe(cr *rabbitmqv1.Rabbitmq) *corev1.Probe {
+	if cr.Spec.RabbitmqLivenessProbe != nil {
+		return cr.Spec.RabbitmqLivenessProbe
+	}
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"rabbitmq-diagnostics", "-q", "ping"}},
+		},
+		InitialDelaySeconds: bootDelaySeconds(cr),
+		TimeoutSeconds:      20,
+		PeriodSeconds:       30,
+		SuccessThreshold:    1,
+		FailureThreshold:    3,
+	}
+}
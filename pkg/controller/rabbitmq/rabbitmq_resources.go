@@ -0,0 +1,125 @@
+package rabbitmq
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/getsentry/raven-go"
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// instanceResource is a resource declared in Rabbitmq instance through management API:
+// RabbitmqUser, RabbitmqVhost, RabbitmqExchange, RabbitmqQueue or RabbitmqBinding
+type instanceResource interface {
+	runtime.Object
+	metav1.Object
+}
+
+// instanceResourceSyncer syncs one kind of instance resources, Reconcile, finalizer and status handling
+// are the same for all kinds and done by reconcileInstanceResource
+type instanceResourceSyncer interface {
+	// newResource returns empty object of the kind
+	newResource() instanceResource
+	// resourceInstance returns name of Rabbitmq the resource belongs to
+	resourceInstance(resource instanceResource) string
+	// resourceStatus returns status of the resource with its conditions and observed generation
+	resourceStatus(resource instanceResource) (status interface{}, conditions *[]rabbitmqv1.RabbitmqCondition, observedGeneration *int64)
+	// syncResource creates or updates the resource in rabbitmq
+	syncResource(reqLogger logr.Logger, resource instanceResource) error
+	// removeResource deletes the resource from rabbitmq
+	removeResource(reqLogger logr.Logger, resource instanceResource, instance *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client) error
+}
+
+// reconcileInstanceResource reads the resource, removes it from rabbitmq when it is deleted
+// or syncs it and writes Ready condition to status
+func (r *ReconcileRabbitmq) reconcileInstanceResource(request reconcile.Request, kind string, finalizer string, syncer instanceResourceSyncer) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling " + kind)
+
+	resource := syncer.newResource()
+	err := r.client.Get(context.TODO(), request.NamespacedName, resource)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+
+	if resource.GetDeletionTimestamp() != nil {
+		return r.finalizeInstanceResource(reqLogger, finalizer, syncer, resource)
+	}
+
+	original := resource.DeepCopyObject().(instanceResource)
+
+	err = syncer.syncResource(reqLogger, resource)
+	status, conditions, observedGeneration := syncer.resourceStatus(resource)
+	setStatusConditionFromError(conditions, rabbitmqv1.RabbitmqConditionReady, err, "SyncFailed")
+	if err == nil {
+		*observedGeneration = resource.GetGeneration()
+	}
+
+	originalStatus, _, _ := syncer.resourceStatus(original)
+	if !reflect.DeepEqual(originalStatus, status) {
+		if statusErr := r.client.Status().Update(context.TODO(), resource); statusErr != nil && !errors.IsNotFound(statusErr) {
+			reqLogger.Info("Status update error", "Error", statusErr.Error())
+			if err == nil {
+				err = statusErr
+			}
+		}
+	}
+
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+	}
+	return reconcile.Result{}, err
+}
+
+// finalizeInstanceResource removes the resource from rabbitmq and then the finalizer,
+// nothing is removed when Rabbitmq is already deleted
+func (r *ReconcileRabbitmq) finalizeInstanceResource(reqLogger logr.Logger, finalizer string, syncer instanceResourceSyncer, resource instanceResource) (reconcile.Result, error) {
+	if !containsString(resource.GetFinalizers(), finalizer) {
+		return reconcile.Result{}, nil
+	}
+
+	instance, apiClient, _, err := r.getInstanceAPIClient(reqLogger, resource.GetNamespace(), syncer.resourceInstance(resource))
+	if err != nil && !errors.IsNotFound(err) {
+		raven.CaptureErrorAndWait(err, nil)
+		return reconcile.Result{}, err
+	}
+
+	if err == nil {
+		if err := syncer.removeResource(reqLogger, resource, instance, apiClient); err != nil && !rabbitmqclient.IsNotFound(err) {
+			raven.CaptureErrorAndWait(err, nil)
+			return reconcile.Result{}, err
+		}
+	}
+
+	resource.SetFinalizers(removeString(resource.GetFinalizers(), finalizer))
+	if err := r.client.Update(context.TODO(), resource); err != nil {
+		reqLogger.Info("Removing finalizer failed", "Finalizer", finalizer)
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// addFinalizer adds finalizer before the resource is created in rabbitmq, status of resource is kept
+func (r *ReconcileRabbitmq) addFinalizer(resource instanceResource, finalizer string) error {
+	if containsString(resource.GetFinalizers(), finalizer) {
+		return nil
+	}
+	resourceCopy := resource.DeepCopyObject().(instanceResource)
+	resourceCopy.SetFinalizers(append(resourceCopy.GetFinalizers(), finalizer))
+	if err := r.client.Update(context.TODO(), resourceCopy); err != nil {
+		return err
+	}
+	resource.SetFinalizers(resourceCopy.GetFinalizers())
+	resource.SetResourceVersion(resourceCopy.GetResourceVersion())
+	return nil
+}
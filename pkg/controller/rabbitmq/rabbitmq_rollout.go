@@ -0,0 +1,256 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// rolloutRequeue pods are not watched directly, rollout polls them while it is in progress
+const rolloutRequeue = 10 * time.Second
+
+// podOrdinal returns ordinal of statefulset pod from its name
+func podOrdinal(pod corev1.Pod) int {
+	ordinal, err := strconv.Atoi(pod.Name[strings.LastIndex(pod.Name, "-")+1:])
+	if err != nil {
+		return -1
+	}
+	return ordinal
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isPodCrashLooping is true when a container of pod waits for restart after repeated failures
+func isPodCrashLooping(pod corev1.Pod) bool {
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+	}
+	return false
+}
+
+// podReadyTransitionWindow Ready condition is set False this long after pod start, later transitions come from probes
+const podReadyTransitionWindow = 5 * time.Second
+
+// podBecameReady is true when pod was ready since it was started: Ready condition is True or changed after pod start
+func podBecameReady(pod corev1.Pod) bool {
+	if pod.Status.StartTime == nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue || condition.LastTransitionTime.Time.After(pod.Status.StartTime.Add(podReadyTransitionWindow))
+		}
+	}
+	return false
+}
+
+// otherNotReadyPod returns name of a not ready pod other than pod, empty string if all others are ready
+func otherNotReadyPod(pods []corev1.Pod, pod corev1.Pod) string {
+	for _, other := range pods {
+		if other.Name != pod.Name && !isPodReady(other) {
+			return other.Name
+		}
+	}
+	return ""
+}
+
+// listPods returns pods of instance sorted by ordinal
+func (r *ReconcileRabbitmq) listPods(cr *rabbitmqv1.Rabbitmq) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), client.InNamespace(cr.Namespace).MatchingLabels(returnLabels(cr)), podList); err != nil {
+		return nil, err
+	}
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool { return podOrdinal(pods[i]) < podOrdinal(pods[j]) })
+	return pods, nil
+}
+
+// reconcileRollout restarts pods running old statefulset revision one by one, statefulset uses OnDelete update strategy.
+// Pod is deleted only when all pods are ready, all nodes are running and rabbitmq reports the node is not critical for quorum or mirror sync
+func (r *ReconcileRabbitmq) reconcileRollout(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces, statefulset *v1.StatefulSet) (reconcile.Result, error) {
+	// revision is known when statefulset controller observed the last spec
+	if statefulset.Status.ObservedGeneration < statefulset.Generation || statefulset.Status.UpdateRevision == "" {
+		return reconcile.Result{RequeueAfter: rolloutRequeue}, nil
+	}
+	revision := statefulset.Status.UpdateRevision
+
+	pods, err := r.listPods(cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var outdated []corev1.Pod
+	for _, pod := range pods {
+		if pod.Labels[v1.ControllerRevisionHashLabelKey] != revision {
+			outdated = append(outdated, pod)
+		}
+	}
+
+	if len(outdated) == 0 {
+		if cr.Status.Rollout != nil {
+			reqLogger.Info("Rollout complete", "Revision", revision)
+		}
+		cr.Status.Rollout = nil
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionRolloutComplete, corev1.ConditionTrue, "RolloutComplete", "")
+		return reconcile.Result{}, nil
+	}
+
+	rollout := &rabbitmqv1.RabbitmqRolloutStatus{
+		Revision:        revision,
+		UpdatedReplicas: int32(len(pods) - len(outdated)),
+	}
+	if cr.Status.Rollout != nil && cr.Status.Rollout.Revision == revision {
+		rollout.RestartingPod = cr.Status.Rollout.RestartingPod
+	}
+	cr.Status.Rollout = rollout
+
+	wait := func(message string) (reconcile.Result, error) {
+		reqLogger.Info("Rollout is waiting", "Revision", revision, "Reason", message)
+		rollout.Message = message
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionRolloutComplete, corev1.ConditionFalse, "RolloutInProgress", message)
+		return reconcile.Result{RequeueAfter: rolloutRequeue}, nil
+	}
+
+	// cookie rotation and inter-node TLS switch restart all pods at once
+	if cookieRotationInProgress(cr) {
+		return wait("erlang cookie rotation is in progress")
+	}
+	if interNodeTLSSwitchPending(statefulset, pods) {
+		return wait("inter-node TLS is switched by a full cluster restart")
+	}
+
+	// scale down stops and removes node, it is finished first
+	if cr.Status.ScaleDown != nil {
+		return wait("scale down is in progress")
+	}
+
+	replicas := int32(1)
+	if statefulset.Spec.Replicas != nil {
+		replicas = *statefulset.Spec.Replicas
+	}
+
+	// pods which never became ready or are crash-looping don't serve queues, they are restarted first to not block
+	// rollout of broken revision. Pod which was ready may hold queue replicas, it is restarted only when it is safe
+	for _, pod := range outdated {
+		if pod.DeletionTimestamp != nil || isPodReady(pod) {
+			continue
+		}
+		reason := ""
+		switch {
+		case isPodCrashLooping(pod):
+			reason = "pod is crash-looping"
+		case !podBecameReady(pod):
+			reason = "pod never became ready"
+		default:
+			if notReady := otherNotReadyPod(pods, pod); notReady != "" {
+				return wait(fmt.Sprintf("pod %s is not ready, pod %s is not ready too", pod.Name, notReady))
+			}
+			if replicas > 1 {
+				if message := r.rolloutBlocker(reqLogger, cr, secretNames, pod, replicas); message != "" {
+					return wait(fmt.Sprintf("pod %s is not ready: %s", pod.Name, message))
+				}
+			}
+			reason = "pod is not ready"
+		}
+		if err := r.restartPod(reqLogger, pod, reason); err != nil {
+			return reconcile.Result{}, err
+		}
+		rollout.RestartingPod = pod.Name
+		return wait(fmt.Sprintf("restarting pod %s, %s", pod.Name, reason))
+	}
+
+	// restarted node must rejoin cluster before next one goes down
+	for _, pod := range pods {
+		if !isPodReady(pod) {
+			return wait(fmt.Sprintf("pod %s is not ready", pod.Name))
+		}
+	}
+	if int32(len(pods)) != replicas {
+		return wait(fmt.Sprintf("%d of %d pods exist", len(pods), replicas))
+	}
+
+	// highest ordinal first, like statefulset controller does
+	pod := outdated[len(outdated)-1]
+
+	if replicas > 1 {
+		if message := r.rolloutBlocker(reqLogger, cr, secretNames, pod, replicas); message != "" {
+			return wait(message)
+		}
+	}
+
+	if err := r.restartPod(reqLogger, pod, "pod runs old revision"); err != nil {
+		return reconcile.Result{}, err
+	}
+	rollout.RestartingPod = pod.Name
+	return wait(fmt.Sprintf("restarting pod %s", pod.Name))
+}
+
+// rolloutBlocker returns why pod can't be restarted now, empty string if it is safe
+func (r *ReconcileRabbitmq) rolloutBlocker(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces, pod corev1.Pod, replicas int32) string {
+	ctx, cancel := context.WithTimeout(context.Background(), managementSyncTimeout)
+	defer cancel()
+
+	apiClient, serviceAccount, err := r.getAPIClient(reqLogger, cr, secretNames)
+	if err != nil {
+		return "management API client: " + err.Error()
+	}
+
+	nodes, err := apiClient.ListNodes(ctx)
+	if err != nil {
+		return "can't list cluster nodes: " + err.Error()
+	}
+	running := int32(0)
+	for _, node := range nodes {
+		if node.Running {
+			running++
+		}
+	}
+	if running < replicas {
+		return fmt.Sprintf("%d of %d nodes are running", running, replicas)
+	}
+
+	// health checks are about the node serving the request, so they are sent to the pod directly
+	podClient, err := r.podAPIClient(&pod, serviceAccount)
+	if err != nil {
+		return "management API client: " + err.Error()
+	}
+	for _, check := range []string{rabbitmqclient.HealthCheckNodeIsQuorumCritical, rabbitmqclient.HealthCheckNodeIsMirrorSyncCritical} {
+		if err := podClient.HealthCheck(ctx, check); err != nil {
+			return fmt.Sprintf("pod %s failed %s check: %s", pod.Name, check, err.Error())
+		}
+	}
+	return ""
+}
+
+func (r *ReconcileRabbitmq) restartPod(reqLogger logr.Logger, pod corev1.Pod, reason string) error {
+	reqLogger.Info("Restarting pod", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name, "Reason", reason)
+	err := r.client.Delete(context.TODO(), &pod)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package rabbitmq
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type rolloutTestPod struct {
+	name         string
+	revision     string
+	ready        bool
+	wasReady     bool
+	crashLooping bool
+}
+
+func newRolloutTestPod(p rolloutTestPod) corev1.Pod {
+	started := metav1.NewTime(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC))
+	readyStatus := corev1.ConditionFalse
+	transition := started
+	if p.ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	if p.ready || p.wasReady {
+		transition = metav1.NewTime(started.Add(time.Minute))
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: p.name, Labels: map[string]string{v1.ControllerRevisionHashLabelKey: p.revision}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			StartTime:  &started,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus, LastTransitionTime: transition}},
+		},
+	}
+	if p.crashLooping {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "rabbitmq", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}}
+	}
+	return pod
+}
+
+func TestReconcileRolloutNotReadyPods(t *testing.T) {
+	tests := []struct {
+		name        string
+		pods        []rolloutTestPod
+		wantDeleted []string
+	}{
+		{
+			name: "pod never ready on old revision is restarted",
+			pods: []rolloutTestPod{
+				{name: "rabbit-0", revision: "new", ready: true},
+				{name: "rabbit-1", revision: "old"},
+				{name: "rabbit-2", revision: "old", wasReady: true},
+			},
+			wantDeleted: []string{"rabbit-1"},
+		},
+		{
+			name: "crash-looping pod is restarted",
+			pods: []rolloutTestPod{
+				{name: "rabbit-0", revision: "new"},
+				{name: "rabbit-1", revision: "old", wasReady: true, crashLooping: true},
+				{name: "rabbit-2", revision: "old", ready: true},
+			},
+			wantDeleted: []string{"rabbit-1"},
+		},
+		{
+			name: "pod which was ready is kept while other pod is not ready",
+			pods: []rolloutTestPod{
+				{name: "rabbit-0", revision: "new"},
+				{name: "rabbit-1", revision: "old", wasReady: true},
+				{name: "rabbit-2", revision: "old", ready: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			k8sClient := &fakeClient{}
+			for _, pod := range test.pods {
+				k8sClient.pods = append(k8sClient.pods, newRolloutTestPod(pod))
+			}
+			r := &ReconcileRabbitmq{client: k8sClient}
+			cr := &rabbitmqv1.Rabbitmq{ObjectMeta: metav1.ObjectMeta{Name: "rabbit", Namespace: "queues"}}
+			replicas := int32(len(test.pods))
+			statefulset := &v1.StatefulSet{
+				Spec:   v1.StatefulSetSpec{Replicas: &replicas},
+				Status: v1.StatefulSetStatus{UpdateRevision: "new"},
+			}
+
+			if _, err := r.reconcileRollout(log, cr, getSecretNames(cr), statefulset); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(k8sClient.deletedPods, test.wantDeleted) {
+				t.Errorf("deleted pods = %v, want %v", k8sClient.deletedPods, test.wantDeleted)
+			}
+		})
+	}
+}
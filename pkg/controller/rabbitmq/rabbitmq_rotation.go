@@ -0,0 +1,461 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// RotatePasswordAnnotation of Rabbitmq, every new value rotates service account password
+	RotatePasswordAnnotation = "rabbitmq.improvado.io/rotate-password"
+
+	// RotateCookieAnnotation of Rabbitmq, every new value rotates erlang cookie with a full cluster restart
+	RotateCookieAnnotation = "rabbitmq.improvado.io/rotate-cookie"
+
+	// ExporterUserAnnotation of pod template, exporter reads credentials on start, so pods are restarted through rollout
+	// when service account user is changed. It is set after the first password rotation
+	ExporterUserAnnotation = "rabbitmq.improvado.io/exporter-user"
+)
+
+const (
+	// rotationRequeue rotation polls pods and nodes while it is in progress
+	rotationRequeue = 10 * time.Second
+
+	// rotationTimeout limits one step of rotation, stop_app is run on every node
+	rotationTimeout = 5 * time.Minute
+)
+
+// keys of service account secret holding credentials of rotation in progress, they survive operator restarts
+const (
+	nextUsernameKey     = "next-username"
+	nextPasswordKey     = "next-password"
+	previousUsernameKey = "previous-username"
+	previousPasswordKey = "previous-password"
+	nextCookieKey       = "next-cookie"
+
+	// cluster restart is recorded with the cookie it switches, request of the last rotation is kept after it is done
+	cookieRotationRequestKey = "cookie-rotation-request"
+	cookieRotationPhaseKey   = "cookie-rotation-phase"
+	clusterRestartKey        = "cluster-restart"
+)
+
+// rotatedUsernameSuffix is added to service account username by every rotation
+var rotatedUsernameSuffix = regexp.MustCompile(`-\d{14}$`)
+
+// nextServiceAccountUsername returns username for rotated password, old and new users exist together until clients are switched
+func nextServiceAccountUsername(username string, now time.Time) string {
+	return rotatedUsernameSuffix.ReplaceAllString(username, "") + "-" + now.UTC().Format("20060102150405")
+}
+
+func cookieRotationInProgress(cr *rabbitmqv1.Rabbitmq) bool {
+	return cr.Status.Credentials != nil && cr.Status.Credentials.CookieRotationPhase != ""
+}
+
+// exporterRestartedOnRotation returns true if pod template carries exporter user, instances which never rotated password
+// don't get the annotation, so operator upgrade doesn't restart them
+func exporterRestartedOnRotation(cr *rabbitmqv1.Rabbitmq) bool {
+	status := cr.Status.Credentials
+	return cr.Spec.RabbitmqPrometheusExporterPort > 0 && status != nil && (status.LastPasswordRotation != nil || status.PasswordRotationPhase != "")
+}
+
+func credentialsStatus(cr *rabbitmqv1.Rabbitmq) *rabbitmqv1.RabbitmqCredentialsStatus {
+	if cr.Status.Credentials == nil {
+		cr.Status.Credentials = &rabbitmqv1.RabbitmqCredentialsStatus{}
+	}
+	return cr.Status.Credentials
+}
+
+// passwordRotationDue checks rotationPeriod against the last rotation or creation of service account secret,
+// returns time left otherwise
+func passwordRotationDue(cr *rabbitmqv1.Rabbitmq, secret *corev1.Secret) (bool, time.Duration) {
+	if cr.Spec.RabbitmqPasswordRotationPeriod == nil {
+		return false, 0
+	}
+	last := secret.CreationTimestamp.Time
+	if cr.Status.Credentials != nil && cr.Status.Credentials.LastPasswordRotation != nil {
+		last = cr.Status.Credentials.LastPasswordRotation.Time
+	}
+	left := time.Until(last.Add(cr.Spec.RabbitmqPasswordRotationPeriod.Duration))
+	if left <= 0 {
+		return true, 0
+	}
+	return false, left
+}
+
+// passwordRotationPhaseFromSecret returns phase of unfinished rotation: previous user is deleted after clients are switched,
+// next user is created before. Secret is written before status, so it is right when status update failed
+func passwordRotationPhaseFromSecret(secret *corev1.Secret) rabbitmqv1.RabbitmqRotationPhase {
+	switch {
+	case len(secret.Data[previousUsernameKey]) > 0:
+		return rabbitmqv1.RabbitmqRotationSwitchingClients
+	case len(secret.Data[nextUsernameKey]) > 0:
+		return rabbitmqv1.RabbitmqRotationCreatingUser
+	}
+	return ""
+}
+
+// rotatingUsernames returns service account users of password rotation in progress, users sync keeps them
+func (r *ReconcileRabbitmq) rotatingUsernames(cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) ([]string, error) {
+	secret, err := r.getSecret(secretNames.ServiceAccount, cr.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var usernames []string
+	for _, key := range []string{nextUsernameKey, previousUsernameKey} {
+		if username := string(secret.Data[key]); username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames, nil
+}
+
+// copyUser creates user with password, tags and permissions of existing user
+func copyUser(ctx context.Context, apiClient rabbitmqclient.Client, from string, to string, password string) error {
+	user, err := apiClient.GetUser(ctx, from)
+	if err != nil {
+		return fmt.Errorf("can't read user %s: %v", from, err)
+	}
+	if err := apiClient.PutUser(ctx, rabbitmqclient.User{Name: to, Password: password, Tags: user.Tags}); err != nil {
+		return fmt.Errorf("can't create user %s: %v", to, err)
+	}
+
+	permissions, err := apiClient.ListUserPermissions(ctx, from)
+	if err != nil {
+		return fmt.Errorf("can't read permissions of %s: %v", from, err)
+	}
+	for _, permission := range permissions {
+		permission.User = to
+		if err := apiClient.PutPermission(ctx, permission); err != nil {
+			return fmt.Errorf("can't set permissions of %s in vhost %s: %v", to, permission.Vhost, err)
+		}
+	}
+
+	topicPermissions, err := apiClient.ListUserTopicPermissions(ctx, from)
+	if err != nil {
+		return fmt.Errorf("can't read topic permissions of %s: %v", from, err)
+	}
+	for _, permission := range topicPermissions {
+		permission.User = to
+		if err := apiClient.PutTopicPermission(ctx, permission); err != nil {
+			return fmt.Errorf("can't set topic permissions of %s in vhost %s: %v", to, permission.Vhost, err)
+		}
+	}
+	return nil
+}
+
+// resumeCookieRotation restores status of unfinished cluster restart from service account secret,
+// phase is written there together with the cookie, so a lost status update doesn't start another restart
+func resumeCookieRotation(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret *corev1.Secret) {
+	phase := rabbitmqv1.RabbitmqRotationPhase(secret.Data[cookieRotationPhaseKey])
+	if phase == "" || cookieRotationInProgress(cr) {
+		return
+	}
+	reqLogger.Info("Cluster restart is resumed from service account secret", "Phase", phase)
+	status := credentialsStatus(cr)
+	status.CookieRotationPhase = phase
+	status.CookieRotationRequest = string(secret.Data[cookieRotationRequestKey])
+	if restartedAt, err := time.Parse(time.RFC3339, string(secret.Data[clusterRestartKey])); err == nil {
+		restartTime := metav1.NewTime(restartedAt)
+		status.LastClusterRestart = &restartTime
+	}
+}
+
+// reconcileRotation rotates service account password and erlang cookie when annotations get new values
+// or rotationPeriod has passed. Credentials of every step are kept in service account secret, steps are retried until they succeed
+func (r *ReconcileRabbitmq) reconcileRotation(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces, statefulset *v1.StatefulSet) (reconcile.Result, error) {
+	secret, err := r.getSecret(secretNames.ServiceAccount, cr.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rotationTimeout)
+	defer cancel()
+
+	// password rotation waits for cluster restart
+	resumeCookieRotation(reqLogger, cr, &secret)
+
+	passwordResult, err := r.rotatePassword(ctx, reqLogger, cr, &secret, statefulset)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	cookieResult, err := r.rotateCookie(ctx, reqLogger, cr, &secret, statefulset)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return mergeResults(passwordResult, cookieResult), nil
+}
+
+// rotatePassword creates a new service account user, switches operator and exporter to it and deletes the previous one.
+// Both users are valid while clients are switched, so nothing loses access
+func (r *ReconcileRabbitmq) rotatePassword(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret *corev1.Secret, statefulset *v1.StatefulSet) (reconcile.Result, error) {
+	status := cr.Status.Credentials
+	if status == nil || status.PasswordRotationPhase == "" {
+		request := cr.Annotations[RotatePasswordAnnotation]
+		// status of a started rotation can be lost, keys of unfinished steps in the secret tell where to continue
+		phase := passwordRotationPhaseFromSecret(secret)
+		if phase == "" {
+			if request == "" || (status != nil && request == status.PasswordRotationRequest) {
+				due, left := passwordRotationDue(cr, secret)
+				if !due {
+					return reconcile.Result{RequeueAfter: left}, nil
+				}
+			}
+			// new user is created through management API
+			if cookieRotationInProgress(cr) || statefulset.Status.ReadyReplicas == 0 {
+				return reconcile.Result{RequeueAfter: rotationRequeue}, nil
+			}
+			reqLogger.Info("Password rotation started", "Request", request)
+			phase = rabbitmqv1.RabbitmqRotationCreatingUser
+		} else {
+			reqLogger.Info("Password rotation is resumed from service account secret", "Phase", phase)
+		}
+		status = credentialsStatus(cr)
+		status.PasswordRotationRequest = request
+		status.PasswordRotationPhase = phase
+	}
+
+	wait := func(message string) (reconcile.Result, error) {
+		reqLogger.Info("Password rotation is waiting", "Phase", status.PasswordRotationPhase, "Reason", message)
+		status.Message = message
+		return reconcile.Result{RequeueAfter: rotationRequeue}, nil
+	}
+	currentCredentials := func() basicAuthCredentials {
+		return basicAuthCredentials{username: string(secret.Data["username"]), password: string(secret.Data["password"])}
+	}
+
+	if status.PasswordRotationPhase == rabbitmqv1.RabbitmqRotationCreatingUser {
+		if len(secret.Data[nextUsernameKey]) == 0 {
+			secret.Data[nextUsernameKey] = []byte(nextServiceAccountUsername(string(secret.Data["username"]), time.Now()))
+			secret.Data[nextPasswordKey] = []byte(randomString(30))
+			if err := r.client.Update(context.TODO(), secret); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		nextUsername := string(secret.Data[nextUsernameKey])
+
+		apiClient, err := r.apiClient(cr, currentCredentials())
+		if err != nil {
+			return wait("management API client: " + err.Error())
+		}
+		reqLogger.Info("Creating service account user", "Username", nextUsername)
+		if err := copyUser(ctx, apiClient, string(secret.Data["username"]), nextUsername, string(secret.Data[nextPasswordKey])); err != nil {
+			return wait(err.Error())
+		}
+		status.PasswordRotationPhase = rabbitmqv1.RabbitmqRotationSwitchingClients
+	}
+
+	if status.PasswordRotationPhase == rabbitmqv1.RabbitmqRotationSwitchingClients {
+		// operator reads the secret on every reconcile, exporter gets it when pod template annotation restarts pods.
+		// User left by an unfinished rotation is deleted first, the next one is switched to by the following rotation
+		if nextUsername := string(secret.Data[nextUsernameKey]); nextUsername != "" && len(secret.Data[previousUsernameKey]) == 0 {
+			reqLogger.Info("Switching service account secret to new user", "Username", nextUsername)
+			secret.Data[previousUsernameKey] = secret.Data["username"]
+			secret.Data[previousPasswordKey] = secret.Data["password"]
+			secret.Data["username"] = secret.Data[nextUsernameKey]
+			secret.Data["password"] = secret.Data[nextPasswordKey]
+			delete(secret.Data, nextUsernameKey)
+			delete(secret.Data, nextPasswordKey)
+			if err := r.client.Update(context.TODO(), secret); err != nil {
+				return reconcile.Result{}, err
+			}
+			return wait("service account secret is switched to " + nextUsername)
+		}
+
+		if cr.Spec.RabbitmqPrometheusExporterPort > 0 {
+			username := string(secret.Data["username"])
+			pods, err := r.listPods(cr)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			for _, pod := range pods {
+				if pod.Annotations[ExporterUserAnnotation] != username || !isPodReady(pod) {
+					return wait(fmt.Sprintf("pod %s is restarting with new exporter credentials", pod.Name))
+				}
+			}
+		}
+		status.PasswordRotationPhase = rabbitmqv1.RabbitmqRotationDeletingUser
+	}
+
+	if previousUsername := string(secret.Data[previousUsernameKey]); previousUsername != "" {
+		if previousUsername != string(secret.Data["username"]) {
+			apiClient, err := r.apiClient(cr, currentCredentials())
+			if err != nil {
+				return wait("management API client: " + err.Error())
+			}
+			reqLogger.Info("Deleting previous service account user", "Username", previousUsername)
+			if err := apiClient.DeleteUser(ctx, previousUsername); err != nil && !rabbitmqclient.IsNotFound(err) {
+				return wait(fmt.Sprintf("can't delete user %s: %v", previousUsername, err))
+			}
+		}
+		delete(secret.Data, previousUsernameKey)
+		delete(secret.Data, previousPasswordKey)
+		if err := r.client.Update(context.TODO(), secret); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	reqLogger.Info("Password rotation complete", "Username", string(secret.Data["username"]))
+	now := metav1.Now()
+	status.LastPasswordRotation = &now
+	status.PasswordRotationPhase = ""
+	status.Message = ""
+	return reconcile.Result{}, nil
+}
+
+// rotateCookie stops nodes from the highest ordinal, so node 0 stops last and doesn't wait for peers when statefulset starts it first,
+// then switches the cookie and restarts all pods at once. Nodes with different cookies can't talk, so the cluster is unavailable until it is formed again.
+// Switching interNodeTLS is done the same way without a new cookie, nodes with and without TLS distribution can't talk either
+func (r *ReconcileRabbitmq) rotateCookie(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secret *corev1.Secret, statefulset *v1.StatefulSet) (reconcile.Result, error) {
+	replicas := int32(1)
+	if statefulset.Spec.Replicas != nil {
+		replicas = *statefulset.Spec.Replicas
+	}
+
+	pods, err := r.listPods(cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	status := cr.Status.Credentials
+	request := cr.Annotations[RotateCookieAnnotation]
+	cookieRequested := request != "" && request != string(secret.Data[cookieRotationRequestKey]) && (status == nil || request != status.CookieRotationRequest)
+	tlsSwitch := interNodeTLSSwitchPending(statefulset, pods)
+	if !cookieRotationInProgress(cr) && !cookieRequested && !tlsSwitch {
+		return reconcile.Result{}, nil
+	}
+	status = credentialsStatus(cr)
+
+	wait := func(message string) (reconcile.Result, error) {
+		reqLogger.Info("Cluster restart is waiting", "Phase", status.CookieRotationPhase, "Reason", message)
+		status.Message = message
+		return reconcile.Result{RequeueAfter: rotationRequeue}, nil
+	}
+
+	if status.CookieRotationPhase == "" {
+		// restart starts from a stable cluster only, pods of inter-node TLS switch are outdated and rollout waits for it
+		interNodeTLS := podInterNodeTLS(statefulset.Spec.Template.Spec)
+		switch {
+		case status.PasswordRotationPhase != "":
+			return wait("password rotation is in progress")
+		case cr.Status.Rollout != nil && !tlsSwitch:
+			return wait("rollout is in progress")
+		case cr.Status.ScaleDown != nil || cr.Spec.RabbitmqReplicas != replicas:
+			return wait("scaling is in progress")
+		}
+		if int32(len(pods)) != replicas {
+			return wait(fmt.Sprintf("%d of %d pods exist", len(pods), replicas))
+		}
+		for _, pod := range pods {
+			// pod restarted with the new setting can't join the others
+			if !isPodReady(pod) && !(tlsSwitch && podInterNodeTLS(pod.Spec) == interNodeTLS) {
+				return wait(fmt.Sprintf("pod %s is not ready", pod.Name))
+			}
+		}
+
+		if cookieRequested {
+			if len(secret.Data[nextCookieKey]) == 0 {
+				secret.Data[nextCookieKey] = []byte(randomString(30))
+			}
+			secret.Data[cookieRotationRequestKey] = []byte(request)
+			reqLogger.Info("Cookie rotation started", "Request", request)
+			status.CookieRotationRequest = request
+		}
+		if tlsSwitch {
+			reqLogger.Info("Full cluster restart started to switch inter-node TLS", "InterNodeTLS", interNodeTLS)
+		}
+		secret.Data[cookieRotationPhaseKey] = []byte(rabbitmqv1.RabbitmqRotationStoppingNodes)
+		if err := r.client.Update(context.TODO(), secret); err != nil {
+			return reconcile.Result{}, err
+		}
+		status.CookieRotationPhase = rabbitmqv1.RabbitmqRotationStoppingNodes
+	}
+
+	if status.CookieRotationPhase == rabbitmqv1.RabbitmqRotationStoppingNodes {
+		// quorum can't be kept when every node goes down
+		pending, err := r.skipPreStopChecks(ctx, reqLogger, cr)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if pending != "" {
+			return wait(fmt.Sprintf("pod %s doesn't see %s annotation yet", pending, SkipPreStopChecksAnnotation))
+		}
+		for i := len(pods) - 1; i >= 0; i-- {
+			reqLogger.Info("Stopping node", "Node", nodeName(cr, pods[i].Name))
+			if _, err := r.execInPod(ctx, &pods[i], "rabbitmqctl", "stop_app"); err != nil {
+				return wait(err.Error())
+			}
+		}
+
+		// restart time is kept in the secret with second precision, like creation time of pods
+		now := metav1.NewTime(time.Now().Truncate(time.Second))
+		// secret has next cookie only when rotation was requested
+		cookieSwitched := len(secret.Data[nextCookieKey]) > 0
+		if cookieSwitched {
+			reqLogger.Info("Switching erlang cookie")
+			secret.Data["cookie"] = secret.Data[nextCookieKey]
+			delete(secret.Data, nextCookieKey)
+		}
+		secret.Data[cookieRotationPhaseKey] = []byte(rabbitmqv1.RabbitmqRotationRestartingCluster)
+		secret.Data[clusterRestartKey] = []byte(now.UTC().Format(time.RFC3339))
+		if err := r.client.Update(context.TODO(), secret); err != nil {
+			return reconcile.Result{}, err
+		}
+		if cookieSwitched {
+			status.LastCookieRotation = &now
+		}
+		// pods created before are restarted with the new cookie and pod template
+		status.LastClusterRestart = &now
+		status.CookieRotationPhase = rabbitmqv1.RabbitmqRotationRestartingCluster
+	}
+
+	// restart started by operator without lastClusterRestart
+	restartedAt := status.LastClusterRestart
+	if restartedAt == nil {
+		restartedAt = status.LastCookieRotation
+	}
+	pods, err = r.listPods(cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && pod.CreationTimestamp.Before(restartedAt) {
+			if err := r.restartPod(reqLogger, pod, "cluster is restarted"); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	apiClient, err := r.apiClient(cr, basicAuthCredentials{username: string(secret.Data["username"]), password: string(secret.Data["password"])})
+	if err != nil {
+		return wait("management API client: " + err.Error())
+	}
+	if _, message := r.clusterReady(ctx, cr, apiClient, replicas); message != "" {
+		return wait(message)
+	}
+
+	if len(secret.Data[cookieRotationPhaseKey]) > 0 {
+		delete(secret.Data, cookieRotationPhaseKey)
+		delete(secret.Data, clusterRestartKey)
+		if err := r.client.Update(context.TODO(), secret); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	reqLogger.Info("Cluster restart complete")
+	status.CookieRotationPhase = ""
+	status.Message = ""
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,180 @@
+package rabbitmq
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func interNodeTLSPod(name string, enabled bool) corev1.Pod {
+	pod := newRolloutTestPod(rolloutTestPod{name: name, revision: "old", ready: true})
+	pod.Annotations = map[string]string{SkipPreStopChecksAnnotation: "true"}
+	pod.Spec.Containers = []corev1.Container{{Name: rabbitmqContainerName, Env: interNodeTLSEnv(&rabbitmqv1.Rabbitmq{Spec: rabbitmqv1.RabbitmqSpec{RabbitmqSSL: rabbitmqv1.RabbitmqSSL{Enabled: true, InterNodeTLS: enabled}}})}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: rabbitmqContainerName, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}}
+	return pod
+}
+
+func TestRotateCookieSwitchesInterNodeTLS(t *testing.T) {
+	r, k8sClient, cr, _ := newManagementTest(&fakeAPIClient{})
+	cr.Spec.RabbitmqSSL = rabbitmqv1.RabbitmqSSL{Enabled: true, ExitingSecret: "rabbit-tls", InterNodeTLS: true}
+	k8sClient.pods = []corev1.Pod{interNodeTLSPod("rabbit-0", false), interNodeTLSPod("rabbit-1", false), interNodeTLSPod("rabbit-2", false)}
+	visible := SkipPreStopChecksAnnotation + "=\"true\"\n"
+	r.podExecutor = &fakePodExecutor{output: map[string]string{"rabbit-0": visible, "rabbit-1": visible, "rabbit-2": visible}}
+
+	replicas := int32(3)
+	statefulset := &v1.StatefulSet{Spec: v1.StatefulSetSpec{Replicas: &replicas}}
+	statefulset.Spec.Template.Spec.Containers = []corev1.Container{{Name: rabbitmqContainerName, Env: interNodeTLSEnv(cr)}}
+	// rollout of the new template waits for the restart
+	cr.Status.Rollout = &rabbitmqv1.RabbitmqRolloutStatus{Revision: "new"}
+	secret := &corev1.Secret{Data: map[string][]byte{"cookie": []byte("old-cookie")}}
+
+	if !interNodeTLSSwitchPending(statefulset, k8sClient.pods) {
+		t.Fatal("switch of inter-node TLS is not detected")
+	}
+
+	if _, err := r.rotateCookie(context.Background(), log, cr, secret, statefulset); err != nil {
+		t.Fatal(err)
+	}
+	status := cr.Status.Credentials
+	if status == nil || status.CookieRotationPhase != rabbitmqv1.RabbitmqRotationRestartingCluster {
+		t.Fatalf("credentials status = %+v, want RestartingCluster phase", status)
+	}
+	if string(secret.Data["cookie"]) != "old-cookie" || status.LastCookieRotation != nil {
+		t.Errorf("cookie is rotated without request")
+	}
+	if status.LastClusterRestart == nil {
+		t.Errorf("lastClusterRestart is not set")
+	}
+	if status.CookieRotationRequest != "" {
+		t.Errorf("cookieRotationRequest = %q, want empty", status.CookieRotationRequest)
+	}
+
+	// pods created before the restart are deleted at once
+	if len(k8sClient.deletedPods) != 3 {
+		t.Errorf("deleted pods %v, want all pods", k8sClient.deletedPods)
+	}
+}
+
+func TestRotatePasswordResumesFromSecret(t *testing.T) {
+	apiClient := &fakeAPIClient{}
+	r, _, cr, _ := newManagementTest(apiClient)
+	// status of the rotation was lost after the secret was switched, the next rotation already created its user
+	secret := &corev1.Secret{Data: map[string][]byte{
+		"username":          []byte("operator-20260102100000"),
+		"password":          []byte("new"),
+		previousUsernameKey: []byte("operator"),
+		previousPasswordKey: []byte("old"),
+		nextUsernameKey:     []byte("operator-20260103100000"),
+		nextPasswordKey:     []byte("next"),
+	}}
+	statefulset := &v1.StatefulSet{}
+
+	if _, err := r.rotatePassword(context.Background(), log, cr, secret, statefulset); err != nil {
+		t.Fatal(err)
+	}
+	if len(apiClient.deletedUsers) != 1 || apiClient.deletedUsers[0] != "operator" {
+		t.Errorf("deleted users %v, want previous user", apiClient.deletedUsers)
+	}
+	if string(secret.Data["username"]) != "operator-20260102100000" {
+		t.Errorf("username = %s, secret is switched before previous user is deleted", secret.Data["username"])
+	}
+	if len(secret.Data[previousUsernameKey]) != 0 || string(secret.Data[nextUsernameKey]) != "operator-20260103100000" {
+		t.Errorf("secret keys %v, want next user kept for the following rotation", secret.Data)
+	}
+	if phase := passwordRotationPhaseFromSecret(secret); phase != rabbitmqv1.RabbitmqRotationCreatingUser {
+		t.Errorf("phase from secret = %q, want CreatingUser", phase)
+	}
+}
+
+func TestRotateCookieResumesFromSecret(t *testing.T) {
+	apiClient := &fakeAPIClient{}
+	r, k8sClient, cr, _ := newManagementTest(apiClient)
+	cr.Annotations = map[string]string{RotateCookieAnnotation: "1"}
+	secretNames := secretResouces{ServiceAccount: "rabbit-service-account", Credentials: "rabbit-credentials"}
+	secret := k8sClient.secrets["queues/rabbit-service-account"]
+	secret.Name, secret.Namespace = "rabbit-service-account", "queues"
+	secret.Data["cookie"] = []byte("old-cookie")
+
+	skipped := map[string]string{SkipPreStopChecksAnnotation: "true"}
+	for _, name := range []string{"rabbit-0", "rabbit-1", "rabbit-2"} {
+		pod := newRolloutTestPod(rolloutTestPod{name: name, ready: true})
+		pod.Annotations = skipped
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: rabbitmqContainerName, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}}
+		k8sClient.pods = append(k8sClient.pods, pod)
+	}
+	executor := &fakePodExecutor{output: map[string]string{}}
+	r.podExecutor = executor
+	replicas := int32(3)
+	statefulset := &v1.StatefulSet{Spec: v1.StatefulSetSpec{Replicas: &replicas}}
+
+	reconcileRotation := func() {
+		t.Helper()
+		if _, err := r.reconcileRotation(log, cr, secretNames, statefulset); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cookie := func() string {
+		return string(k8sClient.secrets["queues/rabbit-service-account"].Data["cookie"])
+	}
+
+	// pods don't see the annotation yet, status of the started rotation is lost
+	reconcileRotation()
+	nextCookie := string(k8sClient.secrets["queues/rabbit-service-account"].Data[nextCookieKey])
+	if nextCookie == "" || cookie() != "old-cookie" {
+		t.Fatalf("next cookie %q and cookie %q, want next cookie written before nodes are stopped", nextCookie, cookie())
+	}
+	cr.Status.Credentials = nil
+
+	// nodes are stopped and the cookie is switched, status is lost again while the cluster is formed
+	for _, name := range []string{"rabbit-0", "rabbit-1", "rabbit-2"} {
+		executor.output[name] = SkipPreStopChecksAnnotation + "=\"true\"\n"
+	}
+	reconcileRotation()
+	if cookie() != nextCookie {
+		t.Fatalf("cookie = %q, want the cookie generated on start %q", cookie(), nextCookie)
+	}
+	if len(k8sClient.deletedPods) != 3 {
+		t.Fatalf("deleted pods %v, want all pods", k8sClient.deletedPods)
+	}
+	cr.Status.Credentials = nil
+	recreated := metav1.NewTime(time.Now().Add(time.Minute))
+	for i := range k8sClient.pods {
+		k8sClient.pods[i].CreationTimestamp = recreated
+	}
+
+	apiClient.nodes = []rabbitmqclient.Node{{Running: true}, {Running: true}, {Running: true}}
+	reconcileRotation()
+	status := cr.Status.Credentials
+	if status == nil || status.CookieRotationPhase != "" || status.CookieRotationRequest != "1" {
+		t.Fatalf("credentials status = %+v, want finished rotation of request 1", status)
+	}
+	if cookie() != nextCookie {
+		t.Errorf("cookie = %q, cookie is rotated twice", cookie())
+	}
+	stopped := 0
+	for _, command := range executor.commands {
+		if strings.HasSuffix(command, "rabbitmqctl stop_app") {
+			stopped++
+		}
+	}
+	if stopped != 3 {
+		t.Errorf("nodes are stopped %d times, want once each: %v", stopped, executor.commands)
+	}
+
+	// request is kept in the secret when status of the finished rotation is lost
+	cr.Status.Credentials = nil
+	reconcileRotation()
+	if cookie() != nextCookie || len(k8sClient.secrets["queues/rabbit-service-account"].Data[nextCookieKey]) != 0 {
+		t.Errorf("cookie is rotated again for the same request")
+	}
+	if cr.Status.Credentials != nil && cr.Status.Credentials.CookieRotationPhase != "" {
+		t.Errorf("cluster restart is started again for the same request")
+	}
+}
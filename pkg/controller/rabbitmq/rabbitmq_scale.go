@@ -0,0 +1,290 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// scaleStepTimeout limits one step of scaling, drain waits for queue leaders to move
+	scaleStepTimeout = 5 * time.Minute
+
+	// scaleRequeue scaling polls pods and nodes while it is in progress
+	scaleRequeue = 10 * time.Second
+)
+
+// nodeName returns rabbitmq node name of statefulset pod, see RABBITMQ_NODENAME
+func nodeName(cr *rabbitmqv1.Rabbitmq, podName string) string {
+	return "rabbit@" + podName + "." + cr.Name + "-discovery." + cr.Namespace + "." + cr.Spec.RabbitmqK8SServiceDiscovery
+}
+
+// statefulsetReplicas returns replicas of statefulset stored in cluster, spec replicas are applied by reconcileScaling
+func statefulsetReplicas(cr *rabbitmqv1.Rabbitmq, statefulset *v1.StatefulSet) int32 {
+	if statefulset.Spec.Replicas == nil || cr.Spec.RabbitmqReplicas >= *statefulset.Spec.Replicas {
+		return cr.Spec.RabbitmqReplicas
+	}
+	return *statefulset.Spec.Replicas
+}
+
+// clusterReady returns why cluster can't be scaled now, empty string if all pods are ready and all nodes are running
+func (r *ReconcileRabbitmq) clusterReady(ctx context.Context, cr *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client, replicas int32) ([]corev1.Pod, string) {
+	pods, err := r.listPods(cr)
+	if err != nil {
+		return nil, "can't list pods: " + err.Error()
+	}
+	if int32(len(pods)) != replicas {
+		return nil, fmt.Sprintf("%d of %d pods exist", len(pods), replicas)
+	}
+	for _, pod := range pods {
+		if !isPodReady(pod) {
+			return nil, fmt.Sprintf("pod %s is not ready", pod.Name)
+		}
+	}
+
+	nodes, err := apiClient.ListNodes(ctx)
+	if err != nil {
+		return nil, "can't list cluster nodes: " + err.Error()
+	}
+	running := int32(0)
+	for _, node := range nodes {
+		if node.Running {
+			running++
+		}
+	}
+	if running < replicas {
+		return nil, fmt.Sprintf("%d of %d nodes are running", running, replicas)
+	}
+	return pods, ""
+}
+
+// reconcileScaling removes nodes one by one when spec replicas are lower than statefulset replicas
+// and adds new nodes to quorum queues when they are higher than the replicas membership was grown to
+func (r *ReconcileRabbitmq) reconcileScaling(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces, statefulset *v1.StatefulSet) (reconcile.Result, error) {
+	replicas := statefulsetReplicas(cr, statefulset)
+
+	wait := func(reason string, message string) (reconcile.Result, error) {
+		reqLogger.Info("Scaling is waiting", "Reason", message)
+		if cr.Status.ScaleDown != nil {
+			cr.Status.ScaleDown.Message = message
+		}
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionScalingComplete, corev1.ConditionFalse, reason, message)
+		return reconcile.Result{RequeueAfter: scaleRequeue}, nil
+	}
+
+	if cr.Spec.RabbitmqReplicas >= replicas {
+		cr.Status.ScaleDown = nil
+	}
+
+	// new cluster or operator upgrade, existing queues are left as they are
+	if cr.Status.QueueMembershipReplicas == 0 {
+		cr.Status.QueueMembershipReplicas = replicas
+	}
+	if cr.Spec.RabbitmqReplicas == replicas && cr.Status.QueueMembershipReplicas >= replicas {
+		cr.Status.QueueMembershipReplicas = replicas
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionScalingComplete, corev1.ConditionTrue, "ScalingComplete", "")
+		return reconcile.Result{}, nil
+	}
+
+	if cookieRotationInProgress(cr) {
+		return wait("WaitingForCookieRotation", "erlang cookie rotation is in progress")
+	}
+
+	// scale down starts after rollout, a started one is finished first
+	if cr.Status.Rollout != nil && cr.Status.ScaleDown == nil {
+		return wait("WaitingForRollout", "rollout is in progress")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scaleStepTimeout)
+	defer cancel()
+
+	apiClient, serviceAccount, err := r.getAPIClient(reqLogger, cr, secretNames)
+	if err != nil {
+		return wait("ManagementAPIUnreachable", "management API client: "+err.Error())
+	}
+
+	if cr.Spec.RabbitmqReplicas == replicas {
+		return r.growQueueMembership(ctx, reqLogger, cr, apiClient, replicas, wait)
+	}
+	return r.scaleDown(ctx, reqLogger, cr, apiClient, serviceAccount, statefulset, replicas, wait)
+}
+
+// growQueueMembership adds nodes started after the last scale up to all quorum queues
+func (r *ReconcileRabbitmq) growQueueMembership(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client,
+	replicas int32, wait func(string, string) (reconcile.Result, error)) (reconcile.Result, error) {
+	// statefulset is scaled up by reconcileInstance, queues are grown when new nodes joined
+	pods, message := r.clusterReady(ctx, cr, apiClient, replicas)
+	if message != "" {
+		return wait("ScaleUpInProgress", message)
+	}
+
+	for ordinal := cr.Status.QueueMembershipReplicas; ordinal < replicas; ordinal++ {
+		node := nodeName(cr, pods[ordinal].Name)
+		reqLogger.Info("Adding node to quorum queues", "Node", node)
+		if _, err := r.execInPod(ctx, &pods[0], "rabbitmq-queues", "grow", node, "all"); err != nil {
+			return wait("ScaleUpFailed", err.Error())
+		}
+		cr.Status.QueueMembershipReplicas = ordinal + 1
+	}
+
+	setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionScalingComplete, corev1.ConditionTrue, "ScalingComplete", "")
+	return reconcile.Result{}, nil
+}
+
+// scaleDown removes the highest ordinal node from cluster and then from statefulset, every step is recorded in status and retried until it succeeds
+func (r *ReconcileRabbitmq) scaleDown(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client, serviceAccount basicAuthCredentials,
+	statefulset *v1.StatefulSet, replicas int32, wait func(string, string) (reconcile.Result, error)) (reconcile.Result, error) {
+	podName := fmt.Sprintf("%s-%d", statefulset.Name, replicas-1)
+
+	if cr.Status.ScaleDown == nil || cr.Status.ScaleDown.Pod != podName {
+		// status with the last step may be lost, scale down continues from the state of node in cluster
+		phase, err := scaleDownPhaseFromCluster(ctx, apiClient, nodeName(cr, podName))
+		if err != nil {
+			return wait("ScaleDownInProgress", "can't list cluster nodes: "+err.Error())
+		}
+		if phase != "" {
+			reqLogger.Info("Scale down continued", "Pod", podName, "Phase", phase)
+			cr.Status.ScaleDown = &rabbitmqv1.RabbitmqScaleDownStatus{
+				Pod:   podName,
+				Node:  nodeName(cr, podName),
+				Phase: phase,
+			}
+		}
+	}
+
+	if cr.Status.ScaleDown == nil || cr.Status.ScaleDown.Pod != podName {
+		// checks are done once, drained node fails them until it is removed
+		pods, message := r.clusterReady(ctx, cr, apiClient, replicas)
+		if message != "" {
+			return wait("ScaleDownInProgress", message)
+		}
+		if replicas > 1 {
+			podClient, err := r.podAPIClient(&pods[replicas-1], serviceAccount)
+			if err != nil {
+				return wait("ScaleDownInProgress", "management API client: "+err.Error())
+			}
+			if err := podClient.HealthCheck(ctx, rabbitmqclient.HealthCheckNodeIsQuorumCritical); err != nil {
+				return wait("ScaleDownRefused", fmt.Sprintf("quorum queues would lose majority without pod %s: %s", podName, err.Error()))
+			}
+		}
+
+		reqLogger.Info("Scale down started", "Pod", podName, "Replicas", cr.Spec.RabbitmqReplicas)
+		cr.Status.ScaleDown = &rabbitmqv1.RabbitmqScaleDownStatus{
+			Pod:   podName,
+			Node:  nodeName(cr, podName),
+			Phase: rabbitmqv1.RabbitmqScaleDownDraining,
+		}
+	}
+	scaleDown := cr.Status.ScaleDown
+
+	// commands are run on the node itself and on pod 0, which is never removed by scale down
+	pod := &corev1.Pod{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: cr.Namespace}, pod)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	podFound := err == nil
+	firstPod := &corev1.Pod{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: statefulset.Name + "-0", Namespace: cr.Namespace}, firstPod); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if scaleDown.Phase == rabbitmqv1.RabbitmqScaleDownDraining {
+		if podFound && replicas > 1 {
+			reqLogger.Info("Draining node", "Node", scaleDown.Node)
+			if _, err := r.execInPod(ctx, pod, "rabbitmq-upgrade", "drain"); err != nil {
+				return wait("ScaleDownInProgress", err.Error())
+			}
+		}
+		scaleDown.Phase = rabbitmqv1.RabbitmqScaleDownShrinkingQueues
+	}
+
+	if scaleDown.Phase == rabbitmqv1.RabbitmqScaleDownShrinkingQueues {
+		if replicas > 1 {
+			reqLogger.Info("Removing node from quorum queues", "Node", scaleDown.Node)
+			if _, err := r.execInPod(ctx, firstPod, "rabbitmq-queues", "shrink", scaleDown.Node); err != nil {
+				return wait("ScaleDownInProgress", err.Error())
+			}
+		}
+		scaleDown.Phase = rabbitmqv1.RabbitmqScaleDownForgettingNode
+	}
+
+	if scaleDown.Phase == rabbitmqv1.RabbitmqScaleDownForgettingNode {
+		if replicas > 1 {
+			if podFound {
+				reqLogger.Info("Stopping node", "Node", scaleDown.Node)
+				if _, err := r.execInPod(ctx, pod, "rabbitmqctl", "stop_app"); err != nil {
+					return wait("ScaleDownInProgress", err.Error())
+				}
+			}
+			member, err := isClusterMember(ctx, apiClient, scaleDown.Node)
+			if err != nil {
+				return wait("ScaleDownInProgress", "can't list cluster nodes: "+err.Error())
+			}
+			if member {
+				reqLogger.Info("Removing node from cluster", "Node", scaleDown.Node)
+				if _, err := r.execInPod(ctx, firstPod, "rabbitmqctl", "forget_cluster_node", scaleDown.Node); err != nil {
+					return wait("ScaleDownInProgress", err.Error())
+				}
+			}
+		}
+		scaleDown.Phase = rabbitmqv1.RabbitmqScaleDownRemovingPod
+	}
+
+	reqLogger.Info("Reducing statefulset replicas", "Replicas", replicas-1)
+	newReplicas := replicas - 1
+	statefulset.Spec.Replicas = &newReplicas
+	if err := r.client.Update(context.TODO(), statefulset); err != nil {
+		return reconcile.Result{}, err
+	}
+	cr.Status.ScaleDown = nil
+	cr.Status.QueueMembershipReplicas = newReplicas
+
+	if newReplicas > cr.Spec.RabbitmqReplicas {
+		return wait("ScaleDownInProgress", fmt.Sprintf("removing %d more nodes", newReplicas-cr.Spec.RabbitmqReplicas))
+	}
+	setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionScalingComplete, corev1.ConditionTrue, "ScalingComplete", "")
+	return reconcile.Result{}, nil
+}
+
+func isClusterMember(ctx context.Context, apiClient rabbitmqclient.Client, node string) (bool, error) {
+	nodes, err := apiClient.ListNodes(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, clusterNode := range nodes {
+		if clusterNode.Name == node {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// scaleDownPhaseFromCluster returns the step to continue scale down of node which was already drained, stopped or forgotten,
+// empty phase if removal of node has not started
+func scaleDownPhaseFromCluster(ctx context.Context, apiClient rabbitmqclient.Client, node string) (rabbitmqv1.RabbitmqScaleDownPhase, error) {
+	nodes, err := apiClient.ListNodes(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, clusterNode := range nodes {
+		if clusterNode.Name != node {
+			continue
+		}
+		// shrink and stop_app can be repeated, drain can't: drained node fails readiness and health checks
+		if !clusterNode.Running || clusterNode.BeingDrained {
+			return rabbitmqv1.RabbitmqScaleDownShrinkingQueues, nil
+		}
+		return "", nil
+	}
+	return rabbitmqv1.RabbitmqScaleDownRemovingPod, nil
+}
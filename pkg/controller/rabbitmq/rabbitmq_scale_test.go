@@ -0,0 +1,51 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+)
+
+func TestScaleDownPhaseFromCluster(t *testing.T) {
+	const node = "rabbit@rabbit-2.rabbit-discovery.queues.svc.cluster.local"
+	tests := []struct {
+		name  string
+		nodes []rabbitmqclient.Node
+		want  rabbitmqv1.RabbitmqScaleDownPhase
+	}{
+		{
+			name:  "running node is not being removed",
+			nodes: []rabbitmqclient.Node{{Name: "rabbit@rabbit-0", Running: true}, {Name: node, Running: true}},
+			want:  "",
+		},
+		{
+			name:  "drained node",
+			nodes: []rabbitmqclient.Node{{Name: "rabbit@rabbit-0", Running: true}, {Name: node, Running: true, BeingDrained: true}},
+			want:  rabbitmqv1.RabbitmqScaleDownShrinkingQueues,
+		},
+		{
+			name:  "stopped node",
+			nodes: []rabbitmqclient.Node{{Name: "rabbit@rabbit-0", Running: true}, {Name: node}},
+			want:  rabbitmqv1.RabbitmqScaleDownShrinkingQueues,
+		},
+		{
+			name:  "forgotten node",
+			nodes: []rabbitmqclient.Node{{Name: "rabbit@rabbit-0", Running: true}},
+			want:  rabbitmqv1.RabbitmqScaleDownRemovingPod,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			phase, err := scaleDownPhaseFromCluster(context.Background(), &fakeAPIClient{nodes: test.nodes}, node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if phase != test.want {
+				t.Errorf("phase = %q, want %q", phase, test.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package rabbitmq
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/base64"
-	"math/rand"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
 
 	"github.com/go-logr/logr"
 	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
@@ -39,13 +43,40 @@ func (r *ReconcileRabbitmq) getSecretData(reqLogger logr.Logger, secretNamespace
 	return string(secretObj.Data[secretDataField]), nil
 }
 
-func (r *ReconcileRabbitmq) reconcileSecrets(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) (secretResouces, error) {
+// getServiceAccountCredentials returns credentials used for management API requests
+func (r *ReconcileRabbitmq) getServiceAccountCredentials(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) (basicAuthCredentials, error) {
+	var serviceAccount basicAuthCredentials
 
-	var secretNames secretResouces
+	secretObj, err := r.getSecret(secretNames.ServiceAccount, cr.Namespace)
+	if err != nil {
+		reqLogger.Info("Service account secret not found", "Namespace", cr.Namespace, "Name", secretNames.ServiceAccount)
+		return serviceAccount, err
+	}
 
-	// standart resource names
-	secretNames.ServiceAccount = cr.Name + "-service-account"
-	secretNames.Credentials = cr.Name + "-credentials"
+	serviceAccount.username = string(secretObj.Data["username"])
+	serviceAccount.password = string(secretObj.Data["password"])
+	return serviceAccount, nil
+}
+
+// getSecretNames returns names of secrets linked in CR or standart names
+func getSecretNames(cr *rabbitmqv1.Rabbitmq) secretResouces {
+	secretNames := secretResouces{
+		ServiceAccount: cr.Name + "-service-account",
+		Credentials:    cr.Name + "-credentials",
+	}
+	if cr.Spec.RabbitmqSecretServiceAccount != "" {
+		secretNames.ServiceAccount = cr.Spec.RabbitmqSecretServiceAccount
+	}
+	if cr.Spec.RabbitmqSecretCredentials != "" {
+		secretNames.Credentials = cr.Spec.RabbitmqSecretCredentials
+	}
+	return secretNames
+}
+
+func (r *ReconcileRabbitmq) reconcileSecrets(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) (secretResouces, error) {
+
+	// standart or linked resource names
+	secretNames := getSecretNames(cr)
 
 	/////////////////////////////////////////////////////////////////
 	// SERVICE ACCOUNT SECRET, CREATING ONCE AT RABBIT INSTALL
@@ -54,7 +85,6 @@ func (r *ReconcileRabbitmq) reconcileSecrets(reqLogger logr.Logger, cr *rabbitmq
 	// check existance of linked or standart service account secret
 	createServiceAccount := false
 	if cr.Spec.RabbitmqSecretServiceAccount != "" {
-		secretNames.ServiceAccount = cr.Spec.RabbitmqSecretServiceAccount
 		secretSAResource := &corev1.Secret{}
 		err := r.client.Get(context.TODO(), types.NamespacedName{Name: secretNames.ServiceAccount, Namespace: cr.Namespace}, secretSAResource)
 
@@ -132,7 +162,6 @@ func (r *ReconcileRabbitmq) reconcileSecrets(reqLogger logr.Logger, cr *rabbitmq
 			reqLogger.Info("User credentials: error happend", err)
 			return secretResouces{}, err
 		}
-		secretNames.Credentials = cr.Spec.RabbitmqSecretCredentials
 	} else {
 		// link empty, search standart credentials secret name
 		reqLogger.Info("User credentials: search for standart resource")
@@ -175,11 +204,77 @@ func (r *ReconcileRabbitmq) reconcileSecrets(reqLogger logr.Logger, cr *rabbitmq
 	return secretNames, nil
 }
 
+// defaultUserConfFile is loaded by rabbitmq from /etc/rabbitmq/conf.d after rabbitmq.conf
+const defaultUserConfFile = "10-default-user.conf"
+
+// defaultUserSecretName secret with default_user and default_pass mounted to conf.d, credentials are not kept in configmap
+func defaultUserSecretName(cr *rabbitmqv1.Rabbitmq) string {
+	return cr.Name + "-default-user-conf"
+}
+
+// reconcileDefaultUserSecret renders service account credentials as default user config, rabbitmq creates the user on first boot.
+// Values are never logged
+func (r *ReconcileRabbitmq) reconcileDefaultUserSecret(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) error {
+	serviceAccount, err := r.getSecret(secretNames.ServiceAccount, cr.Namespace)
+	if err != nil {
+		reqLogger.Info("Service account secret not found", "Namespace", cr.Namespace, "Name", secretNames.ServiceAccount)
+		return err
+	}
+	for _, key := range []string{"username", "password", "cookie"} {
+		if len(serviceAccount.Data[key]) == 0 {
+			return fmt.Errorf("secret %s has empty %s", secretNames.ServiceAccount, key)
+		}
+	}
+	username := string(serviceAccount.Data["username"])
+	password := string(serviceAccount.Data["password"])
+	// config value ends with the line
+	if strings.ContainsAny(username+password, "\r\n") {
+		return fmt.Errorf("secret %s has line breaks in username or password", secretNames.ServiceAccount)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaultUserSecretName(cr),
+			Namespace: cr.Namespace,
+			Labels:    returnLabels(cr),
+		},
+		Data: map[string][]byte{
+			defaultUserConfFile: []byte("default_user = " + username + "\ndefault_pass = " + password + "\n"),
+		},
+	}
+	if err := controllerutil.SetControllerReference(cr, secret, r.scheme); err != nil {
+		return err
+	}
+
+	found := &corev1.Secret{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, found)
+	if err != nil && apierrors.IsNotFound(err) {
+		reqLogger.Info("Creating default user secret", "Namespace", secret.Namespace, "Name", secret.Name)
+		return r.client.Create(context.TODO(), secret)
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(found.Data, secret.Data) {
+		return nil
+	}
+	reqLogger.Info("Updating default user secret", "Namespace", secret.Namespace, "Name", secret.Name)
+	found.Data = secret.Data
+	return r.client.Update(context.TODO(), found)
+}
+
+// randomString returns letters and digits read from crypto/rand, used for passwords and cookies
 func randomString(l int) string {
 	var letterRunes = []rune("ABCDEFGHIJKLMNOabcdefghijklmn67890PQRSTUVWXYZ12345opqrstuvwxyz")
+	max := big.NewInt(int64(len(letterRunes)))
 	b := make([]rune, l)
 	for i := range b {
-		b[i] = letterRunes[rand.Intn(len(letterRunes))]
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			// system random source is broken, a weak password must not be created
+			panic(err)
+		}
+		b[i] = letterRunes[n.Int64()]
 	}
 	return string(b)
 }
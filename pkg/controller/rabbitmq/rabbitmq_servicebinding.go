@@ -0,0 +1,225 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// bindingSecretType of secrets in servicebinding.io layout, applications mount them as /bindings/<name>
+const bindingSecretType corev1.SecretType = "servicebinding.io/rabbitmq"
+
+const (
+	// bindingLabel marks binding secrets of RabbitmqUsers of instance, they are copied to bindingNamespaces
+	bindingLabel = "rabbitmq.improvado.io/binding"
+
+	// labels of binding copies in other namespaces, they have no owner and are found by these labels
+	bindingInstanceLabel  = "rabbitmq.improvado.io/binding-instance"
+	bindingNamespaceLabel = "rabbitmq.improvado.io/binding-namespace"
+)
+
+// userBindingSecretName binding of RabbitmqUser, named like its credentials secret "<name>-user-credentials"
+func userBindingSecretName(user *rabbitmqv1.RabbitmqUser) string {
+	return user.Name + "-user-binding"
+}
+
+// amqpURI builds connection URI, credentials are escaped as userinfo and vhost as one path segment, so "/" becomes "%2F"
+func amqpURI(scheme string, username string, password string, host string, port int, vhost string) string {
+	return scheme + "://" + url.UserPassword(username, password).String() + "@" + host + ":" + strconv.Itoa(port) + "/" + rabbitmqclient.EscapeVhost(vhost)
+}
+
+// bindingSecretData returns servicebinding.io entries for the client service of instance and management service
+func bindingSecretData(cr *rabbitmqv1.Rabbitmq, username string, password string, vhost string) map[string][]byte {
+	if vhost == "" {
+		vhost = "/"
+	}
+	host := cr.Name + "." + cr.Namespace
+	port := amqpPort(cr)
+	scheme := "amqp"
+	if port == 5671 {
+		scheme = "amqps"
+	}
+	managementHost := cr.Name + "-api." + cr.Namespace
+	managementURI := "http://" + managementHost + ":15672"
+
+	data := map[string][]byte{
+		"type":     []byte("rabbitmq"),
+		"provider": []byte("rabbitmq-operator"),
+		"host":     []byte(host),
+		"port":     []byte(strconv.Itoa(port)),
+		"username": []byte(username),
+		"password": []byte(password),
+		"vhost":    []byte(vhost),
+		"uri":      []byte(amqpURI(scheme, username, password, host, port, vhost)),
+	}
+	if cr.Spec.RabbitmqSSL.Enabled {
+		data["amqps-uri"] = []byte(amqpURI("amqps", username, password, host, 5671, vhost))
+		managementURI = "https://" + managementHost + ":15671"
+	}
+	data["management-uri"] = []byte(managementURI)
+	return data
+}
+
+// reconcileBindingSecret creates or updates binding secret owned by owner
+func (r *ReconcileRabbitmq) reconcileBindingSecret(reqLogger logr.Logger, owner metav1.Object, secret *corev1.Secret) error {
+	if err := controllerutil.SetControllerReference(owner, secret, r.scheme); err != nil {
+		return err
+	}
+
+	found := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, found)
+	if err != nil && apierrors.IsNotFound(err) {
+		reqLogger.Info("Creating binding secret", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+		return r.client.Create(context.TODO(), secret)
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(found.Data, secret.Data) && reflect.DeepEqual(found.Labels, secret.Labels) {
+		return nil
+	}
+	reqLogger.Info("Updating binding secret", "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+	found.Data = secret.Data
+	found.Labels = secret.Labels
+	return r.client.Update(context.TODO(), found)
+}
+
+// bindingCopySelector selects copies of binding secrets of instance
+func bindingCopySelector(cr *rabbitmqv1.Rabbitmq) string {
+	return labels.SelectorFromSet(labels.Set{bindingInstanceLabel: cr.Name, bindingNamespaceLabel: cr.Namespace}).String()
+}
+
+// bindingCopyNamespaces returns sorted bindingNamespaces without namespace of instance and duplicates
+func bindingCopyNamespaces(cr *rabbitmqv1.Rabbitmq) []string {
+	var namespaces []string
+	for _, namespace := range cr.Spec.RabbitmqBindingNamespaces {
+		if namespace != cr.Namespace && !containsString(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// reconcileBindingCopies copies binding secrets of RabbitmqUsers of instance to bindingNamespaces and deletes copies
+// which are no longer needed. Namespace is recorded in status before the first copy is written there and only recorded
+// namespaces are searched for stale copies. Copies are written with uncached clientset, manager cache is limited to operator namespace
+func (r *ReconcileRabbitmq) reconcileBindingCopies(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) error {
+	namespaces := bindingCopyNamespaces(cr)
+	if len(namespaces) == 0 && len(cr.Status.BindingNamespaces) == 0 {
+		return nil
+	}
+	if r.clientset == nil {
+		return fmt.Errorf("kubernetes clientset is not configured")
+	}
+
+	recorded := append([]string{}, cr.Status.BindingNamespaces...)
+	for _, namespace := range namespaces {
+		if !containsString(recorded, namespace) {
+			recorded = append(recorded, namespace)
+		}
+	}
+	sort.Strings(recorded)
+	if !reflect.DeepEqual(recorded, cr.Status.BindingNamespaces) {
+		cr.Status.BindingNamespaces = recorded
+		if err := r.client.Status().Update(context.TODO(), cr); err != nil {
+			return err
+		}
+	}
+
+	sources := &corev1.SecretList{}
+	if err := r.client.List(context.TODO(), client.InNamespace(cr.Namespace).MatchingLabels(mergeMaps(returnLabels(cr), map[string]string{bindingLabel: "true"})), sources); err != nil {
+		return err
+	}
+
+	for _, namespace := range recorded {
+		expected := map[string]bool{}
+		if containsString(namespaces, namespace) {
+			for _, source := range sources.Items {
+				expected[source.Name] = true
+				if err := r.copyBindingSecret(reqLogger, cr, source, namespace); err != nil {
+					return err
+				}
+			}
+		}
+		if err := r.deleteStaleBindingCopies(reqLogger, cr, namespace, expected); err != nil {
+			return err
+		}
+	}
+	cr.Status.BindingNamespaces = namespaces
+	return nil
+}
+
+func (r *ReconcileRabbitmq) copyBindingSecret(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, source corev1.Secret, namespace string) error {
+	copyLabels := map[string]string{bindingInstanceLabel: cr.Name, bindingNamespaceLabel: cr.Namespace}
+
+	found, err := r.clientset.CoreV1().Secrets(namespace).Get(source.Name, metav1.GetOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		reqLogger.Info("Copying binding secret", "Secret.Namespace", namespace, "Secret.Name", source.Name)
+		_, err = r.clientset.CoreV1().Secrets(namespace).Create(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: source.Name, Namespace: namespace, Labels: copyLabels},
+			Type:       source.Type,
+			Data:       source.Data,
+		})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	// secret with the same name created by someone else is not overwritten
+	if found.Labels[bindingInstanceLabel] != cr.Name || found.Labels[bindingNamespaceLabel] != cr.Namespace {
+		return fmt.Errorf("secret %s/%s exists and is not a binding copy of %s/%s", namespace, source.Name, cr.Namespace, cr.Name)
+	}
+	if reflect.DeepEqual(found.Data, source.Data) {
+		return nil
+	}
+	reqLogger.Info("Updating binding secret copy", "Secret.Namespace", namespace, "Secret.Name", source.Name)
+	found.Data = source.Data
+	_, err = r.clientset.CoreV1().Secrets(namespace).Update(found)
+	return err
+}
+
+// deleteStaleBindingCopies deletes copies of instance in namespace which are not expected
+func (r *ReconcileRabbitmq) deleteStaleBindingCopies(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, namespace string, expected map[string]bool) error {
+	copies, err := r.clientset.CoreV1().Secrets(namespace).List(metav1.ListOptions{LabelSelector: bindingCopySelector(cr)})
+	if err != nil {
+		return err
+	}
+	for _, copied := range copies.Items {
+		if expected[copied.Name] {
+			continue
+		}
+		reqLogger.Info("Deleting binding secret copy", "Secret.Namespace", copied.Namespace, "Secret.Name", copied.Name)
+		if err := r.clientset.CoreV1().Secrets(copied.Namespace).Delete(copied.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteBindingCopies removes copies of a deleted Rabbitmq from recorded namespaces, they have no owner reference
+func (r *ReconcileRabbitmq) deleteBindingCopies(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) error {
+	if r.clientset == nil {
+		return nil
+	}
+	for _, namespace := range cr.Status.BindingNamespaces {
+		if err := r.deleteStaleBindingCopies(reqLogger, cr, namespace, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,6 +17,49 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// amqpServicePorts returns AMQP and AMQPS ports enabled in rabbitmq.conf
+func amqpServicePorts(cr *rabbitmqv1.Rabbitmq) []corev1.ServicePort {
+	var ports []corev1.ServicePort
+	if !cr.Spec.RabbitmqSSL.Enabled || !cr.Spec.RabbitmqSSL.DisablePlaintext {
+		ports = append(ports, corev1.ServicePort{
+			TargetPort: intstr.IntOrString{IntVal: 5672},
+			Port:       5672,
+			Protocol:   corev1.ProtocolTCP,
+			Name:       "amqp",
+		})
+	}
+	if cr.Spec.RabbitmqSSL.Enabled {
+		ports = append(ports, corev1.ServicePort{
+			TargetPort: intstr.IntOrString{IntVal: 5671},
+			Port:       5671,
+			Protocol:   corev1.ProtocolTCP,
+			Name:       "amqps",
+		})
+	}
+	return ports
+}
+
+// managementServicePorts returns management API ports, plain HTTP is kept for operator requests
+func managementServicePorts(cr *rabbitmqv1.Rabbitmq) []corev1.ServicePort {
+	ports := []corev1.ServicePort{
+		{
+			TargetPort: intstr.IntOrString{IntVal: 15672},
+			Port:       15672,
+			Protocol:   corev1.ProtocolTCP,
+			Name:       "api",
+		},
+	}
+	if cr.Spec.RabbitmqSSL.Enabled {
+		ports = append(ports, corev1.ServicePort{
+			TargetPort: intstr.IntOrString{IntVal: 15671},
+			Port:       15671,
+			Protocol:   corev1.ProtocolTCP,
+			Name:       "api-tls",
+		})
+	}
+	return ports
+}
+
 func (r *ReconcileRabbitmq) reconcileService(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, service *corev1.Service) (reconcile.Result, error) {
 	reqLogger.Info("Started reconciling service", "Service.Namespace", service.Namespace, "Service.Name", service.Name)
 
@@ -54,6 +97,10 @@ func (r *ReconcileRabbitmq) reconcileService(reqLogger logr.Logger, cr *rabbitmq
 		found.Spec.Selector = service.Spec.Selector
 	}
 
+	if found.Spec.PublishNotReadyAddresses != service.Spec.PublishNotReadyAddresses {
+		found.Spec.PublishNotReadyAddresses = service.Spec.PublishNotReadyAddresses
+	}
+
 	if !reflect.DeepEqual(found.Labels, service.Labels) {
 		found.Labels = service.Labels
 	}
@@ -103,20 +150,16 @@ func (r *ReconcileRabbitmq) reconcileDiscoveryService(reqLogger logr.Logger, cr
 		Spec: corev1.ServiceSpec{
 			Type:     corev1.ServiceTypeClusterIP,
 			Selector: returnLabels(cr),
-			Ports: []corev1.ServicePort{
-				{
-					TargetPort: intstr.IntOrString{IntVal: 5672},
-					Port:       5672,
-					Protocol:   corev1.ProtocolTCP,
-					Name:       "amqp",
-				},
-				{
+			// peers are resolved before pods are ready, nodes wait for each other on start
+			PublishNotReadyAddresses: true,
+			Ports: append(amqpServicePorts(cr),
+				corev1.ServicePort{
 					TargetPort: intstr.IntOrString{IntVal: 4369},
 					Port:       4369,
 					Protocol:   corev1.ProtocolTCP,
 					Name:       "empd",
 				},
-			},
+			),
 		},
 	}
 
@@ -134,26 +177,15 @@ func (r *ReconcileRabbitmq) reconcileHAService(reqLogger logr.Logger, cr *rabbit
 		Spec: corev1.ServiceSpec{
 			Type:     corev1.ServiceTypeClusterIP,
 			Selector: returnLabels(cr),
-			Ports: []corev1.ServicePort{
-				{
-					TargetPort: intstr.IntOrString{IntVal: 5672},
-					Port:       5672,
-					Protocol:   corev1.ProtocolTCP,
-					Name:       "amqp",
-				},
-				{
+			Ports: append(append(amqpServicePorts(cr),
+				corev1.ServicePort{
 					TargetPort: intstr.IntOrString{IntVal: 4369},
 					Port:       4369,
 					Protocol:   corev1.ProtocolTCP,
 					Name:       "empd",
-				},
-				{
-					TargetPort: intstr.IntOrString{IntVal: 15672},
-					Port:       15672,
-					Protocol:   corev1.ProtocolTCP,
-					Name:       "api",
-				},
-			},
+				}),
+				managementServicePorts(cr)...,
+			),
 		},
 	}
 
@@ -175,14 +207,7 @@ func (r *ReconcileRabbitmq) reconcileHTTPService(reqLogger logr.Logger, cr *rabb
 		Spec: corev1.ServiceSpec{
 			Type:     corev1.ServiceTypeClusterIP,
 			Selector: returnLabels(cr),
-			Ports: []corev1.ServicePort{
-				{
-					TargetPort: intstr.IntOrString{IntVal: 15672},
-					Port:       15672,
-					Protocol:   corev1.ProtocolTCP,
-					Name:       "api",
-				},
-			},
+			Ports:    managementServicePorts(cr),
 		},
 	}
 
@@ -0,0 +1,105 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// SkipPreStopChecksAnnotation of pod makes preStop hook exit at once, set on pods of deleted Rabbitmq
+// where quorum can't be kept because all nodes go down
+const SkipPreStopChecksAnnotation = "rabbitmq.improvado.io/skip-prestop-checks"
+
+// podInfoPath downward API volume with pod annotations, it is updated while pod is running
+const podInfoPath = "/etc/podinfo"
+
+// podInfoRequeue kubelet refreshes downward API volume in its sync loop, annotation is visible in pods within a minute or so
+const podInfoRequeue = 10 * time.Second
+
+// preStopScript waits until every quorum queue has enough replicas without this node and moves
+// leaders and clients to other nodes. Errors are ignored, stopped or forgotten node can't answer
+const preStopScript = `if ! grep -q '^%s="true"$' %s/annotations 2>/dev/null; then
+  rabbitmq-upgrade await_online_quorum_plus_one -t %d
+  rabbitmq-upgrade drain -t %d
+fi
+true
+`
+
+// preStopTimeouts splits termination grace period: half for quorum wait, a quarter for drain,
+// the rest is left for the node to stop after SIGTERM
+func preStopTimeouts(gracePeriodSeconds int64) (quorumTimeout int64, drainTimeout int64) {
+	return gracePeriodSeconds / 2, gracePeriodSeconds / 4
+}
+
+// preStopHandler returns preStop hook of rabbitmq container
+func preStopHandler(cr *rabbitmqv1.Rabbitmq) *corev1.Handler {
+	gracePeriodSeconds := int64(rabbitmqv1.DefaultRabbitmqTerminationGracePeriodSeconds)
+	if cr.Spec.RabbitmqTerminationGracePeriodSeconds != nil {
+		gracePeriodSeconds = *cr.Spec.RabbitmqTerminationGracePeriodSeconds
+	}
+	quorumTimeout, drainTimeout := preStopTimeouts(gracePeriodSeconds)
+	return &corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"sh", "-c", fmt.Sprintf(preStopScript, SkipPreStopChecksAnnotation, podInfoPath, quorumTimeout, drainTimeout)},
+		},
+	}
+}
+
+// isRabbitmqContainerRunning is false for pods which are not started or are terminating, their preStop hook
+// doesn't run or already runs
+func isRabbitmqContainerRunning(pod corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == rabbitmqContainerName {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// skipPreStopChecks annotates pods, so they don't wait for quorum when the whole cluster is stopped.
+// Downward API volume is refreshed lazily, name of the first running pod whose annotations file doesn't have it yet
+// is returned and pods must not be deleted until it is empty
+func (r *ReconcileRabbitmq) skipPreStopChecks(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) (string, error) {
+	pods, err := r.listPods(cr)
+	if err != nil {
+		return "", err
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Annotations[SkipPreStopChecksAnnotation] == "true" {
+			continue
+		}
+		reqLogger.Info("Disabling preStop checks", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[SkipPreStopChecksAnnotation] = "true"
+		if err := r.client.Update(context.TODO(), pod); err != nil && !apierrors.IsNotFound(err) {
+			return "", err
+		}
+	}
+
+	for i := range pods {
+		if !isRabbitmqContainerRunning(pods[i]) {
+			continue
+		}
+		annotations, err := r.execInPod(ctx, &pods[i], "cat", podInfoPath+"/annotations")
+		if err != nil {
+			reqLogger.Info("Reading pod annotations failed", "Pod.Name", pods[i].Name, "Error", err.Error())
+			return pods[i].Name, nil
+		}
+		if !strings.Contains("\n"+annotations+"\n", "\n"+SkipPreStopChecksAnnotation+"=\"true\"\n") {
+			return pods[i].Name, nil
+		}
+	}
+	return "", nil
+}
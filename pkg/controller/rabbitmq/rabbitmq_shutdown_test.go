@@ -0,0 +1,97 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakePodExecutor returns output of command by pod name and records commands
+type fakePodExecutor struct {
+	output   map[string]string
+	err      map[string]error
+	commands []string
+}
+
+func (e *fakePodExecutor) Exec(ctx context.Context, pod *corev1.Pod, container string, command ...string) (string, error) {
+	e.commands = append(e.commands, pod.Name+": "+strings.Join(command, " "))
+	return e.output[pod.Name], e.err[pod.Name]
+}
+
+func runningPod(name string, annotations map[string]string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: rabbitmqContainerName, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+		},
+	}
+}
+
+func TestPreStopTimeouts(t *testing.T) {
+	quorumTimeout, drainTimeout := preStopTimeouts(3600)
+	if quorumTimeout+drainTimeout >= 3600 {
+		t.Errorf("quorum %ds and drain %ds don't leave time to stop the node", quorumTimeout, drainTimeout)
+	}
+	if quorumTimeout != 1800 || drainTimeout != 900 {
+		t.Errorf("timeouts = %d, %d, want 1800, 900", quorumTimeout, drainTimeout)
+	}
+}
+
+func TestSkipPreStopChecks(t *testing.T) {
+	skipped := map[string]string{SkipPreStopChecksAnnotation: "true"}
+	visible := "kubernetes.io/config.seen=\"2026-01-02T10:00:00Z\"\n" + SkipPreStopChecksAnnotation + "=\"true\"\n"
+	stopped := runningPod("rabbit-2", skipped)
+	stopped.Status.ContainerStatuses[0].State = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+
+	tests := []struct {
+		name        string
+		pods        []corev1.Pod
+		executor    *fakePodExecutor
+		wantPending string
+		wantUpdated int
+	}{
+		{
+			name:        "annotation is written and not visible yet",
+			pods:        []corev1.Pod{runningPod("rabbit-0", nil), runningPod("rabbit-1", nil)},
+			executor:    &fakePodExecutor{output: map[string]string{"rabbit-0": "kubernetes.io/config.seen=\"2026-01-02T10:00:00Z\"\n"}},
+			wantPending: "rabbit-0",
+			wantUpdated: 2,
+		},
+		{
+			name:        "annotation is visible in every running pod",
+			pods:        []corev1.Pod{runningPod("rabbit-0", skipped), runningPod("rabbit-1", skipped), stopped},
+			executor:    &fakePodExecutor{output: map[string]string{"rabbit-0": visible, "rabbit-1": visible}},
+			wantPending: "",
+		},
+		{
+			name:        "annotations file can't be read",
+			pods:        []corev1.Pod{runningPod("rabbit-0", skipped)},
+			executor:    &fakePodExecutor{err: map[string]error{"rabbit-0": errors.New("container not found")}},
+			wantPending: "rabbit-0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, k8sClient, cr, _ := newManagementTest(&fakeAPIClient{})
+			k8sClient.pods = test.pods
+			r.podExecutor = test.executor
+
+			pending, err := r.skipPreStopChecks(context.Background(), log, cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pending != test.wantPending {
+				t.Errorf("pending = %q, want %q", pending, test.wantPending)
+			}
+			if len(k8sClient.updatedPods) != test.wantUpdated {
+				t.Errorf("updated %d pods, want %d", len(k8sClient.updatedPods), test.wantUpdated)
+			}
+		})
+	}
+}
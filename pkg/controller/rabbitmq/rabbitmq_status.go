@@ -0,0 +1,142 @@
+package rabbitmq
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func getStatusCondition(conditions []rabbitmqv1.RabbitmqCondition, conditionType rabbitmqv1.RabbitmqConditionType) *rabbitmqv1.RabbitmqCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// setStatusCondition adds or updates condition, transition time changes only when status changes
+func setStatusCondition(conditions *[]rabbitmqv1.RabbitmqCondition, conditionType rabbitmqv1.RabbitmqConditionType, conditionStatus corev1.ConditionStatus, reason string, message string) {
+	condition := getStatusCondition(*conditions, conditionType)
+	if condition == nil {
+		*conditions = append(*conditions, rabbitmqv1.RabbitmqCondition{Type: conditionType})
+		condition = &(*conditions)[len(*conditions)-1]
+	}
+
+	if condition.Status != conditionStatus {
+		condition.Status = conditionStatus
+		condition.LastTransitionTime = metav1.Now()
+	}
+	condition.Reason = reason
+	condition.Message = message
+}
+
+// setStatusConditionFromError sets condition to True if err is nil, False with error message otherwise
+func setStatusConditionFromError(conditions *[]rabbitmqv1.RabbitmqCondition, conditionType rabbitmqv1.RabbitmqConditionType, err error, failedReason string) {
+	if err != nil {
+		setStatusCondition(conditions, conditionType, corev1.ConditionFalse, failedReason, err.Error())
+		return
+	}
+	setStatusCondition(conditions, conditionType, corev1.ConditionTrue, "Succeeded", "")
+}
+
+func conditionStatusFromBool(value bool) corev1.ConditionStatus {
+	if value {
+		return corev1.ConditionTrue
+	}
+	return corev1.ConditionFalse
+}
+
+// reconcileClusterStatus reads cluster state from management API, returns error only if the API can't be used
+func (r *ReconcileRabbitmq) reconcileClusterStatus(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) error {
+	apiClient, _, err := r.getAPIClient(reqLogger, cr, secretNames)
+	if err != nil {
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionManagementAPIReachable, corev1.ConditionFalse, "CredentialsNotFound", err.Error())
+		return err
+	}
+
+	overview, err := apiClient.Overview(ctx)
+	if err != nil {
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionManagementAPIReachable, corev1.ConditionFalse, "RequestFailed", err.Error())
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionClusterFormed, corev1.ConditionUnknown, "ManagementAPIUnreachable", "")
+		return err
+	}
+	setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionManagementAPIReachable, corev1.ConditionTrue, "Succeeded", "")
+	cr.Status.RabbitmqVersion = overview.RabbitmqVersion
+	cr.Status.ErlangVersion = overview.ErlangVersion
+
+	nodes, err := apiClient.ListNodes(ctx)
+	if err != nil {
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionClusterFormed, corev1.ConditionUnknown, "RequestFailed", err.Error())
+		return nil
+	}
+
+	cr.Status.Nodes = []rabbitmqv1.RabbitmqNodeStatus{}
+	runningNodes := 0
+	partitioned := false
+	for _, node := range nodes {
+		cr.Status.Nodes = append(cr.Status.Nodes, rabbitmqv1.RabbitmqNodeStatus{
+			Name:       node.Name,
+			Running:    node.Running,
+			Type:       node.Type,
+			Partitions: node.Partitions,
+		})
+		if node.Running {
+			runningNodes++
+		}
+		if len(node.Partitions) > 0 {
+			partitioned = true
+		}
+	}
+
+	switch {
+	case partitioned:
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionClusterFormed, corev1.ConditionFalse, "NetworkPartition", "network partition detected")
+	case int32(runningNodes) < cr.Spec.RabbitmqReplicas:
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionClusterFormed, corev1.ConditionFalse, "NodesMissing",
+			strconv.Itoa(runningNodes)+" of "+strconv.Itoa(int(cr.Spec.RabbitmqReplicas))+" nodes running")
+	default:
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionClusterFormed, corev1.ConditionTrue, "AllNodesRunning", "")
+	}
+	return nil
+}
+
+// updateStatus sets replica conditions and result of reconcile, writes status only if it was changed
+func (r *ReconcileRabbitmq) updateStatus(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, originalStatus *rabbitmqv1.RabbitmqStatus, reconcileErr error) error {
+	statefulset := &v1.StatefulSet{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, statefulset)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	cr.Status.ReadyReplicas = statefulset.Status.ReadyReplicas
+
+	setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionAvailable, conditionStatusFromBool(cr.Status.ReadyReplicas > 0), "ReadyReplicas",
+		strconv.Itoa(int(cr.Status.ReadyReplicas))+" replicas ready")
+	setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionAllReplicasReady, conditionStatusFromBool(cr.Status.ReadyReplicas == cr.Spec.RabbitmqReplicas), "ReadyReplicas",
+		strconv.Itoa(int(cr.Status.ReadyReplicas))+" of "+strconv.Itoa(int(cr.Spec.RabbitmqReplicas))+" replicas ready")
+	setStatusConditionFromError(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionReconcileSuccess, reconcileErr, "ReconcileFailed")
+
+	if reconcileErr == nil {
+		cr.Status.ObservedGeneration = cr.Generation
+	}
+
+	if reflect.DeepEqual(originalStatus, &cr.Status) {
+		return nil
+	}
+
+	reqLogger.Info("Updating status", "Namespace", cr.Namespace, "Name", cr.Name)
+	err = r.client.Status().Update(context.TODO(), cr)
+	if err != nil && apierrors.IsNotFound(err) {
+		// CR was deleted while reconciling
+		return nil
+	}
+	return err
+}
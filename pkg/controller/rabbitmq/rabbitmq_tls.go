@@ -0,0 +1,121 @@
+package rabbitmq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TLSHashAnnotation of pod template, hash of certificate the pods were started with, rotated certificate restarts pods
+const TLSHashAnnotation = "rabbitmq.improvado.io/tls-hash"
+
+const (
+	tlsVolumeName = "rabbit-tls"
+	// tlsPath is outside of /etc/rabbitmq, init container copies config there
+	tlsPath = "/etc/rabbitmq-tls"
+)
+
+// interNodeTLSConfigFile is copied to /etc/rabbitmq with other config files by init container
+const interNodeTLSConfigFile = "inter_node_tls.config"
+
+// serverErlArgsEnv of rabbitmq container, it has TLS distribution arguments when interNodeTLS is enabled
+const serverErlArgsEnv = "RABBITMQ_SERVER_ADDITIONAL_ERL_ARGS"
+
+// interNodeTLSEnv switches erlang distribution of the server and CLI tools to TLS.
+// Commands run with exec, like probes, preStop hook and operator scaling, get container environment
+func interNodeTLSEnv(cr *rabbitmqv1.Rabbitmq) []corev1.EnvVar {
+	if !cr.Spec.RabbitmqSSL.Enabled || !cr.Spec.RabbitmqSSL.InterNodeTLS {
+		return nil
+	}
+	erlArgs := "-proto_dist inet_tls -ssl_dist_optfile /etc/rabbitmq/" + interNodeTLSConfigFile
+	return []corev1.EnvVar{
+		{Name: serverErlArgsEnv, Value: erlArgs},
+		{Name: "RABBITMQ_CTL_ERL_ARGS", Value: erlArgs},
+	}
+}
+
+// podInterNodeTLS is true when rabbitmq container of pod or pod template runs erlang distribution over TLS
+func podInterNodeTLS(podSpec corev1.PodSpec) bool {
+	for _, container := range podSpec.Containers {
+		if container.Name != rabbitmqContainerName {
+			continue
+		}
+		for _, env := range container.Env {
+			if env.Name == serverErlArgsEnv {
+				return strings.Contains(env.Value, "inet_tls")
+			}
+		}
+	}
+	return false
+}
+
+// interNodeTLSSwitchPending is true when a pod runs erlang distribution not matching statefulset template. Nodes with
+// and without TLS can't talk, so the setting is switched by a full cluster restart instead of rollout
+func interNodeTLSSwitchPending(statefulset *v1.StatefulSet, pods []corev1.Pod) bool {
+	enabled := podInterNodeTLS(statefulset.Spec.Template.Spec)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && podInterNodeTLS(pod.Spec) != enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// amqpPort returns port of the listener clients and probes use
+func amqpPort(cr *rabbitmqv1.Rabbitmq) int {
+	if cr.Spec.RabbitmqSSL.Enabled && cr.Spec.RabbitmqSSL.DisablePlaintext {
+		return 5671
+	}
+	return 5672
+}
+
+// tlsVolume mounts certificate secret, only keys used by rabbitmq.conf are projected
+func tlsVolume(cr *rabbitmqv1.Rabbitmq) corev1.Volume {
+	ssl := cr.Spec.RabbitmqSSL
+	return corev1.Volume{
+		Name: tlsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: ssl.ExitingSecret,
+				Items: []corev1.KeyToPath{
+					{Key: ssl.Cacertfile, Path: ssl.Cacertfile},
+					{Key: ssl.Certfile, Path: ssl.Certfile},
+					{Key: ssl.Keyfile, Path: ssl.Keyfile},
+				},
+			},
+		},
+	}
+}
+
+// tlsSecretHash returns hash of certificate files, empty string if TLS is disabled.
+// Rabbitmq reads certificates on start, so the hash in pod template restarts pods through rollout when certificate is renewed
+func (r *ReconcileRabbitmq) tlsSecretHash(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq) (string, error) {
+	ssl := cr.Spec.RabbitmqSSL
+	if !ssl.Enabled {
+		return "", nil
+	}
+
+	secret, err := r.getSecret(ssl.ExitingSecret, cr.Namespace)
+	if err != nil {
+		reqLogger.Info("Can't get TLS secret", "Secret.Namespace", cr.Namespace, "Secret.Name", ssl.ExitingSecret)
+		return "", err
+	}
+
+	hash := sha256.New()
+	for _, key := range []string{ssl.Cacertfile, ssl.Certfile, ssl.Keyfile} {
+		data, found := secret.Data[key]
+		if !found || len(data) == 0 {
+			return "", fmt.Errorf("secret %s has empty %s", ssl.ExitingSecret, key)
+		}
+		hash.Write([]byte(key + "\x00"))
+		hash.Write(data)
+		hash.Write([]byte("\x00"))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
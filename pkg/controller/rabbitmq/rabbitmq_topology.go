@@ -0,0 +1,44 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"reflect"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+)
+
+// topologyVhost returns vhost of exchange, queue or binding, empty vhost falls to default vhost of Rabbitmq
+func topologyVhost(instance *rabbitmqv1.Rabbitmq, vhost string) string {
+	if vhost != "" {
+		return vhost
+	}
+	return instance.DefaultVhost()
+}
+
+// topologyDurable returns durable flag, exchanges and queues are durable by default
+func topologyDurable(durable *bool) bool {
+	if durable == nil {
+		return true
+	}
+	return *durable
+}
+
+// argumentsEqual compares arguments from CR and from management API, numbers are compared as JSON numbers
+func argumentsEqual(a map[string]interface{}, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	normalize := func(arguments map[string]interface{}) interface{} {
+		var normalized interface{}
+		data, err := json.Marshal(arguments)
+		if err != nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &normalized); err != nil {
+			return nil
+		}
+		return normalized
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
@@ -1,62 +1,262 @@
 package rabbitmq
 
 import (
+	"bytes"
 	"context"
-	"net"
-	"time"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/getsentry/raven-go"
 	"github.com/go-logr/logr"
 	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
 )
 
-// Like policies, we need to remove all users and add them from secret
+// rabbitmqHashingAlgorithm is the default password hashing of rabbitmq, the only one operator computes and checks
+const rabbitmqHashingAlgorithm = "rabbit_password_hashing_sha256"
 
-func (r *ReconcileRabbitmq) syncUsersCredentials(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) error {
+// rabbitmqPasswordMatches checks password against rabbit_password_hashing_sha256 hash:
+// base64 of 4 bytes salt followed by sha256(salt + password)
+func rabbitmqPasswordMatches(password string, user rabbitmqclient.User) bool {
+	if user.HashingAlgorithm != rabbitmqHashingAlgorithm {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(user.PasswordHash)
+	if err != nil || len(decoded) != 4+sha256.Size {
+		return false
+	}
+	salt := decoded[:4]
+	hash := sha256.Sum256(append(append([]byte{}, salt...), []byte(password)...))
+	return bytes.Equal(decoded[4:], hash[:])
+}
 
-	// get service account credentials
-	var serviceAccount basicAuthCredentials
+// rabbitmqPasswordHash returns rabbit_password_hashing_sha256 hash of password with random salt,
+// users are created with the hash, so plaintext passwords aren't sent to management API
+func rabbitmqPasswordHash(password string) string {
+	salt := make([]byte, 4)
+	if _, err := rand.Read(salt); err != nil {
+		// system random source is broken, hashes must not share salt
+		panic(err)
+	}
+	hash := sha256.Sum256(append(append([]byte{}, salt...), []byte(password)...))
+	return base64.StdEncoding.EncodeToString(append(salt, hash[:]...))
+}
+
+// credentialsEntry is a structured value of credentials secret, JSON object under username key.
+// Plain values are passwords of administrators without managed permissions
+type credentialsEntry struct {
+	Password         string              `json:"password,omitempty"`
+	PasswordHash     string              `json:"passwordHash,omitempty"`
+	HashingAlgorithm string              `json:"hashingAlgorithm,omitempty"`
+	Tags             rabbitmqclient.Tags `json:"tags,omitempty"`
+	// nil leaves permissions as they are, empty list removes all of them
+	Permissions []rabbitmqv1.RabbitmqUserPermission `json:"permissions,omitempty"`
+}
+
+// parseCredentialsEntry reads value of credentials secret, values starting with "{" are structured entries
+func parseCredentialsEntry(value []byte) (credentialsEntry, error) {
+	trimmed := bytes.TrimSpace(value)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return credentialsEntry{Password: string(value), Tags: rabbitmqclient.Tags{"administrator"}}, nil
+	}
+
+	entry := credentialsEntry{}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entry); err != nil {
+		return entry, fmt.Errorf("invalid JSON entry: %v", err)
+	}
+	switch {
+	case entry.Password == "" && entry.PasswordHash == "":
+		return entry, fmt.Errorf("password or passwordHash is required")
+	case entry.Password != "" && entry.PasswordHash != "":
+		return entry, fmt.Errorf("password and passwordHash can't be set together")
+	case entry.Password != "" && entry.HashingAlgorithm != "":
+		return entry, fmt.Errorf("hashingAlgorithm is only used with passwordHash")
+	}
+	if entry.PasswordHash != "" {
+		if entry.HashingAlgorithm == "" {
+			entry.HashingAlgorithm = rabbitmqHashingAlgorithm
+		}
+		if entry.HashingAlgorithm != rabbitmqHashingAlgorithm {
+			return entry, fmt.Errorf("hashingAlgorithm %s is not supported, only %s", entry.HashingAlgorithm, rabbitmqHashingAlgorithm)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.PasswordHash)
+		if err != nil || len(decoded) != 4+sha256.Size {
+			return entry, fmt.Errorf("passwordHash must be base64 of 4 bytes salt and sha256 digest")
+		}
+	}
+	for _, permission := range entry.Permissions {
+		if permission.Vhost == "" {
+			return entry, fmt.Errorf("permissions need vhost")
+		}
+	}
+	if entry.Tags == nil {
+		entry.Tags = rabbitmqclient.Tags{}
+	}
+	return entry, nil
+}
+
+// credentialsUser returns user to write to rabbitmq, false if existing user already matches entry.
+// Password hash of unchanged user is kept, so its salt doesn't change on every sync
+func credentialsUser(name string, entry credentialsEntry, existing rabbitmqclient.User, found bool) (rabbitmqclient.User, bool) {
+	user := rabbitmqclient.User{Name: name, Tags: entry.Tags, HashingAlgorithm: rabbitmqHashingAlgorithm}
 
-	username, err := r.getSecretData(reqLogger, cr.Namespace, secretNames.ServiceAccount, "username")
-	serviceAccount.username = username
+	passwordChanged := true
+	if found {
+		if entry.PasswordHash != "" {
+			passwordChanged = existing.PasswordHash != entry.PasswordHash || existing.HashingAlgorithm != entry.HashingAlgorithm
+		} else {
+			passwordChanged = !rabbitmqPasswordMatches(entry.Password, existing)
+		}
+	}
+
+	switch {
+	case !passwordChanged:
+		user.PasswordHash = existing.PasswordHash
+		user.HashingAlgorithm = existing.HashingAlgorithm
+	case entry.PasswordHash != "":
+		user.PasswordHash = entry.PasswordHash
+		user.HashingAlgorithm = entry.HashingAlgorithm
+	default:
+		user.PasswordHash = rabbitmqPasswordHash(entry.Password)
+	}
+
+	return user, passwordChanged || !reflect.DeepEqual(existing.Tags, entry.Tags)
+}
+
+// syncUserPermissions sets vhost permissions of user and removes permissions in other vhosts
+func syncUserPermissions(ctx context.Context, reqLogger logr.Logger, apiClient rabbitmqclient.Client, userName string, permissions []rabbitmqv1.RabbitmqUserPermission) error {
+	permissionsRabbit, err := apiClient.ListUserPermissions(ctx, userName)
 	if err != nil {
-		reqLogger.Info("Users: auth username not found")
-		raven.CaptureErrorAndWait(err, nil)
 		return err
 	}
 
-	password, err := r.getSecretData(reqLogger, cr.Namespace, secretNames.ServiceAccount, "password")
-	serviceAccount.password = password
+	permissionsCR := map[string]rabbitmqclient.Permission{}
+	for _, permission := range permissions {
+		permissionsCR[permission.Vhost] = rabbitmqclient.Permission{User: userName, Vhost: permission.Vhost, Configure: permission.Configure, Write: permission.Write, Read: permission.Read}
+	}
+
+	for _, permissionRabbit := range permissionsRabbit {
+		permissionCR, ok := permissionsCR[permissionRabbit.Vhost]
+		if !ok {
+			reqLogger.Info("Removing permissions of " + userName + " from " + permissionRabbit.Vhost + " vhost")
+			if err := apiClient.DeletePermission(ctx, permissionRabbit.Vhost, userName); err != nil {
+				return err
+			}
+			continue
+		}
+		if permissionCR.Configure == permissionRabbit.Configure && permissionCR.Write == permissionRabbit.Write && permissionCR.Read == permissionRabbit.Read {
+			delete(permissionsCR, permissionRabbit.Vhost)
+		}
+	}
+
+	for _, permissionCR := range permissionsCR {
+		reqLogger.Info("Setting permissions of " + userName + " in " + permissionCR.Vhost + " vhost")
+		if err := apiClient.PutPermission(ctx, permissionCR); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncUserTopicPermissions writes changed topic permissions and removes those missing in spec, they are identified by vhost and exchange.
+// API removes topic permissions only for the whole vhost, so a vhost with a removed exchange is cleared and its other exchanges are set again
+func syncUserTopicPermissions(ctx context.Context, reqLogger logr.Logger, apiClient rabbitmqclient.Client, userName string, permissions []rabbitmqv1.RabbitmqUserTopicPermission) error {
+	permissionsRabbit, err := apiClient.ListUserTopicPermissions(ctx, userName)
 	if err != nil {
-		reqLogger.Info("Users: auth password not found")
-		raven.CaptureErrorAndWait(err, nil)
 		return err
 	}
 
-	// wait http connection to api port
-	timeout := time.Duration(5 * time.Second)
+	permissionsCR := map[string]rabbitmqclient.TopicPermission{}
+	for _, permission := range permissions {
+		permissionsCR[permission.Vhost+"/"+permission.Exchange] = rabbitmqclient.TopicPermission{User: userName, Vhost: permission.Vhost, Exchange: permission.Exchange, Write: permission.Write, Read: permission.Read}
+	}
 
-	_, err = net.DialTimeout("tcp", r.apiServiceHostname(cr), timeout)
+	permissionsFound := map[string]rabbitmqclient.TopicPermission{}
+	clearVhosts := map[string]bool{}
+	for _, permissionRabbit := range permissionsRabbit {
+		if _, ok := permissionsCR[permissionRabbit.Vhost+"/"+permissionRabbit.Exchange]; !ok {
+			clearVhosts[permissionRabbit.Vhost] = true
+			continue
+		}
+		permissionsFound[permissionRabbit.Vhost+"/"+permissionRabbit.Exchange] = permissionRabbit
+	}
+
+	for vhost := range clearVhosts {
+		reqLogger.Info("Removing topic permissions of " + userName + " from " + vhost + " vhost")
+		if err := apiClient.DeleteTopicPermissions(ctx, vhost, userName); err != nil {
+			return err
+		}
+	}
+
+	for key, permissionCR := range permissionsCR {
+		permissionRabbit, ok := permissionsFound[key]
+		if ok && !clearVhosts[permissionCR.Vhost] && permissionRabbit.Write == permissionCR.Write && permissionRabbit.Read == permissionCR.Read {
+			continue
+		}
+		reqLogger.Info("Setting topic permissions of " + userName + " for " + permissionCR.Exchange + " in " + permissionCR.Vhost + " vhost")
+		if err := apiClient.PutTopicPermission(ctx, permissionCR); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Like policies, we need to remove all users and add them from secret
+
+func (r *ReconcileRabbitmq) syncUsersCredentials(ctx context.Context, reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, secretNames secretResouces) error {
+
+	// get service account credentials
+	apiClient, serviceAccount, err := r.getAPIClient(reqLogger, cr, secretNames)
 	if err != nil {
-		reqLogger.Info("Rabbitmq API service failed", "Service name", r.apiServiceHostname(cr), "Error", err.Error())
+		reqLogger.Info("Users: service account not found")
 		raven.CaptureErrorAndWait(err, nil)
 		return err
 	}
+
 	reqLogger.Info("Users: Using API service: "+r.apiServiceAddress(cr), "username", serviceAccount.username)
 
 	// get user from secret
 	usersSecret, err := r.getSecret(secretNames.Credentials, cr.Namespace)
+	if err != nil {
+		// without the secret every user would be removed
+		reqLogger.Info("Users: credentials secret not found", "Error", err.Error())
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
 	reqLogger.Info("Users from secret", "CRD", cr.Name, "SecretNames", secretNames, "Credentials secret", usersSecret.Name, "ServiceAccount", serviceAccount.username)
 
 	// get users from rabbit api
 	reqLogger.Info("Reading all users from rabbitmq")
-	usersRabbit, err := r.apiUserList(reqLogger, cr, serviceAccount)
+	usersRabbit, err := apiClient.ListUsers(ctx)
 	if err != nil {
 		reqLogger.Info("Error while receiving users list", "Error", err.Error())
 		raven.CaptureErrorAndWait(err, nil)
 		return err
 	}
 
+	// users managed by RabbitmqUser resources are not removed
+	managedUsers, err := r.listManagedUsernames(cr)
+	if err != nil {
+		reqLogger.Info("Error while listing RabbitmqUser resources", "Error", err.Error())
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	// old and new service account users exist together while password is rotated
+	rotatingUsers, err := r.rotatingUsernames(cr, secretNames)
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+	managedUsers = append(managedUsers, rotatingUsers...)
+
 	reqLogger.Info("Sync users started")
 
 	// search users to remove
@@ -71,9 +271,9 @@ func (r *ReconcileRabbitmq) syncUsersCredentials(ctx context.Context, reqLogger
 		}
 
 		// user from RabbitMQ not found in secret resource, so add to remove list
-		if (!userFound) && (userRabbitName.Name != serviceAccount.username) {
+		if (!userFound) && (userRabbitName.Name != serviceAccount.username) && !containsString(managedUsers, userRabbitName.Name) {
 			reqLogger.Info("Removing " + userRabbitName.Name)
-			err = r.apiUserRemove(reqLogger, cr, serviceAccount, rabbitmqUserStruct{Name: userRabbitName.Name})
+			err = apiClient.DeleteUser(ctx, userRabbitName.Name)
 			if err != nil {
 				raven.CaptureErrorAndWait(err, nil)
 				return err
@@ -83,17 +283,43 @@ func (r *ReconcileRabbitmq) syncUsersCredentials(ctx context.Context, reqLogger
 
 	reqLogger.Info("Uploading users from secret")
 
-	// add new users to Rabbit
-	for userName, userPassword := range usersSecret.Data {
-		reqLogger.Info("Adding user " + userName)
+	existingUsers := map[string]rabbitmqclient.User{}
+	for _, user := range usersRabbit {
+		existingUsers[user.Name] = user
+	}
 
-		err = r.apiUserAdd(reqLogger, cr, serviceAccount, rabbitmqUserStruct{Name: userName, Password: string(userPassword), Tags: "administrator"})
+	// invalid entry doesn't stop sync of other users
+	var failed []string
+	for userName, value := range usersSecret.Data {
+		entry, err := parseCredentialsEntry(value)
 		if err != nil {
-			reqLogger.Info("Error adding user "+userName, "Error", err)
-			raven.CaptureErrorAndWait(err, nil)
-			return err
+			reqLogger.Info("Invalid credentials of user "+userName, "Error", err.Error())
+			failed = append(failed, userName+": "+err.Error())
+			continue
+		}
+
+		// users are written only when password or tags differ
+		existing, found := existingUsers[userName]
+		if user, changed := credentialsUser(userName, entry, existing, found); changed {
+			reqLogger.Info("Updating user " + userName + " with tags: " + user.Tags.String())
+			if err := apiClient.PutUser(ctx, user); err != nil {
+				reqLogger.Info("Error adding user "+userName, "Error", err)
+				raven.CaptureErrorAndWait(err, nil)
+				return err
+			}
+		}
+
+		if entry.Permissions != nil {
+			if err := syncUserPermissions(ctx, reqLogger, apiClient, userName, entry.Permissions); err != nil {
+				reqLogger.Info("Error setting permissions of user "+userName, "Error", err)
+				raven.CaptureErrorAndWait(err, nil)
+				return err
+			}
 		}
 	}
 
+	if len(failed) > 0 {
+		return fmt.Errorf("secret %s has invalid users: %s", secretNames.Credentials, strings.Join(failed, "; "))
+	}
 	return nil
 }
@@ -0,0 +1,22 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+)
+
+func TestSyncUsersCredentialsWithoutSecret(t *testing.T) {
+	apiClient := &fakeAPIClient{users: []rabbitmqclient.User{{Name: "operator"}, {Name: "app"}, {Name: "reports"}}}
+	r, _, cr, _ := newManagementTest(apiClient)
+	secretNames := secretResouces{ServiceAccount: "rabbit-service-account", Credentials: "rabbit-credentials"}
+
+	// credentials secret is missing, users of the broker are kept
+	if err := r.syncUsersCredentials(context.Background(), log, cr, secretNames); err == nil {
+		t.Error("missing credentials secret is not reported")
+	}
+	if len(apiClient.deletedUsers) != 0 {
+		t.Errorf("deleted users %v, want none", apiClient.deletedUsers)
+	}
+}
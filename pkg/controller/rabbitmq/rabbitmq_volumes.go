@@ -0,0 +1,189 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// volumeExpansionRequeue PVCs are not watched, expansion polls them while it is in progress
+	volumeExpansionRequeue = 30 * time.Second
+
+	// statefulsetDeletionRequeue orphaning pods takes a few seconds, then statefulset is created again
+	statefulsetDeletionRequeue = 5 * time.Second
+)
+
+// dataVolumeName is the name of statefulset volume claim template, PVCs are named rabbit-data-<name>-<ordinal>
+const dataVolumeName = "rabbit-data"
+
+// templateVolumeSize returns storage request of data volume claim template
+func templateVolumeSize(statefulset *v1.StatefulSet) (resource.Quantity, bool) {
+	for _, template := range statefulset.Spec.VolumeClaimTemplates {
+		if template.Name == dataVolumeName {
+			size, found := template.Spec.Resources.Requests[corev1.ResourceStorage]
+			return size, found
+		}
+	}
+	return resource.Quantity{}, false
+}
+
+// listDataVolumes returns PVCs of all pods statefulset ever had, PVCs of removed pods are kept for scale up
+func (r *ReconcileRabbitmq) listDataVolumes(cr *rabbitmqv1.Rabbitmq) ([]corev1.PersistentVolumeClaim, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.client.List(context.TODO(), client.InNamespace(cr.Namespace).MatchingLabels(returnLabels(cr)), pvcList); err != nil {
+		return nil, err
+	}
+	var pvcs []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcList.Items {
+		if strings.HasPrefix(pvc.Name, dataVolumeName+"-"+cr.Name+"-") {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	return pvcs, nil
+}
+
+// volumeExpansionAllowed checks allowVolumeExpansion of PVC storage class
+func (r *ReconcileRabbitmq) volumeExpansionAllowed(pvc corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	if r.clientset == nil {
+		return false, fmt.Errorf("kubernetes clientset is not configured")
+	}
+	storageClass, err := r.clientset.StorageV1().StorageClasses().Get(*pvc.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+}
+
+// expandVolumes resizes PVCs when volume_size is larger than statefulset volume claim template.
+// Template can't be changed, so statefulset is deleted with orphaned pods and created again with new size.
+// Returns true if statefulset was deleted
+func (r *ReconcileRabbitmq) expandVolumes(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, statefulset *v1.StatefulSet) (bool, error) {
+	templateSize, found := templateVolumeSize(statefulset)
+	if !found {
+		return false, nil
+	}
+
+	size := cr.Spec.RabbitmqVolumeSize
+	switch size.Cmp(templateSize) {
+	case 0:
+		return false, nil
+	case -1:
+		reqLogger.Info("Volume size can't be decreased", "Size", size.String(), "CurrentSize", templateSize.String())
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionVolumesExpanded, corev1.ConditionFalse, "ShrinkNotSupported",
+			"volume_size can't be decreased from "+templateSize.String())
+		return false, nil
+	}
+
+	pvcs, err := r.listDataVolumes(cr)
+	if err != nil {
+		return false, err
+	}
+
+	// nothing is changed unless every volume can be expanded
+	for _, pvc := range pvcs {
+		allowed, err := r.volumeExpansionAllowed(pvc)
+		if err != nil {
+			return false, err
+		}
+		if !allowed {
+			reqLogger.Info("Storage class doesn't allow volume expansion", "PVC.Name", pvc.Name)
+			setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionVolumesExpanded, corev1.ConditionFalse, "ExpansionNotSupported",
+				fmt.Sprintf("storage class of %s doesn't allow volume expansion", pvc.Name))
+			return false, nil
+		}
+	}
+
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if current.Cmp(size) >= 0 {
+			continue
+		}
+		reqLogger.Info("Expanding PVC", "PVC.Namespace", pvc.Namespace, "PVC.Name", pvc.Name, "Size", size.String())
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		if err := r.client.Update(context.TODO(), pvc); err != nil {
+			return false, err
+		}
+	}
+
+	reqLogger.Info("Deleting statefulset to update volume claim template, pods are kept", "statefulset.Namespace", statefulset.Namespace, "statefulset.Name", statefulset.Name)
+	if err := r.client.Delete(context.TODO(), statefulset, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil {
+		return false, err
+	}
+	cr.Status.VolumeExpansion = &rabbitmqv1.RabbitmqVolumeExpansionStatus{
+		Size:    size,
+		Volumes: int32(len(pvcs)),
+		Message: "recreating statefulset",
+	}
+	setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionVolumesExpanded, corev1.ConditionFalse, "ExpansionInProgress", "recreating statefulset")
+	return true, nil
+}
+
+// reconcileVolumeExpansionStatus reports how many PVCs reached requested capacity
+func (r *ReconcileRabbitmq) reconcileVolumeExpansionStatus(reqLogger logr.Logger, cr *rabbitmqv1.Rabbitmq, statefulset *v1.StatefulSet) (reconcile.Result, error) {
+	size, found := templateVolumeSize(statefulset)
+	if !found || size.Cmp(cr.Spec.RabbitmqVolumeSize) != 0 {
+		// condition is set by expandVolumes
+		return reconcile.Result{}, nil
+	}
+
+	pvcs, err := r.listDataVolumes(cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	expansion := &rabbitmqv1.RabbitmqVolumeExpansionStatus{
+		Size:    size,
+		Volumes: int32(len(pvcs)),
+	}
+	var pending []string
+	for _, pvc := range pvcs {
+		if pvc.Status.Phase != corev1.ClaimBound {
+			// capacity of unbound claim is set when it is bound with requested size
+			expansion.Volumes--
+			continue
+		}
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(size) >= 0 {
+			expansion.ExpandedVolumes++
+			continue
+		}
+		pending = append(pending, pvc.Name)
+		for _, condition := range pvc.Status.Conditions {
+			if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+				expansion.Message = "file system resize of " + pvc.Name + " is pending, it is done when the pod is restarted"
+			}
+		}
+	}
+
+	if len(pending) == 0 {
+		if cr.Status.VolumeExpansion != nil {
+			reqLogger.Info("Volumes expanded", "Size", size.String())
+		}
+		cr.Status.VolumeExpansion = nil
+		setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionVolumesExpanded, corev1.ConditionTrue, "VolumesExpanded", "")
+		return reconcile.Result{}, nil
+	}
+
+	if expansion.Message == "" {
+		expansion.Message = "waiting for " + strings.Join(pending, ", ")
+	}
+	reqLogger.Info("Volume expansion is in progress", "Size", size.String(), "Pending", strings.Join(pending, ", "))
+	cr.Status.VolumeExpansion = expansion
+	setStatusCondition(&cr.Status.Conditions, rabbitmqv1.RabbitmqConditionVolumesExpanded, corev1.ConditionFalse, "ExpansionInProgress", expansion.Message)
+	return reconcile.Result{RequeueAfter: volumeExpansionRequeue}, nil
+}
@@ -0,0 +1,172 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getsentry/raven-go"
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// RabbitmqBindingFinalizer removes binding from rabbitmq before RabbitmqBinding deletion
+const RabbitmqBindingFinalizer = "rabbitmq.improvado.io/binding"
+
+// AddRabbitmqBinding creates a new RabbitmqBinding Controller and adds it to the Manager
+func AddRabbitmqBinding(mgr manager.Manager) error {
+	return addRabbitmqBinding(mgr, &ReconcileRabbitmqBinding{ReconcileRabbitmq{client: mgr.GetClient(), scheme: mgr.GetScheme()}})
+}
+
+func addRabbitmqBinding(mgr manager.Manager, reconciler reconcile.Reconciler) error {
+	c, err := controller.New("rabbitmqbinding-controller", mgr, controller.Options{Reconciler: reconciler})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &rabbitmqv1.RabbitmqBinding{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileRabbitmqBinding{}
+
+// ReconcileRabbitmqBinding reconciles a RabbitmqBinding object
+type ReconcileRabbitmqBinding struct {
+	// shares client and management API helpers with Rabbitmq reconciler
+	ReconcileRabbitmq
+}
+
+// Reconcile binds queue or exchange to source exchange in rabbitmq
+func (r *ReconcileRabbitmqBinding) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	return r.reconcileInstanceResource(request, "RabbitmqBinding", RabbitmqBindingFinalizer, r)
+}
+
+func (r *ReconcileRabbitmqBinding) newResource() instanceResource {
+	return &rabbitmqv1.RabbitmqBinding{}
+}
+
+func (r *ReconcileRabbitmqBinding) resourceInstance(resource instanceResource) string {
+	return resource.(*rabbitmqv1.RabbitmqBinding).Spec.RabbitmqInstance
+}
+
+func (r *ReconcileRabbitmqBinding) resourceStatus(resource instanceResource) (interface{}, *[]rabbitmqv1.RabbitmqCondition, *int64) {
+	binding := resource.(*rabbitmqv1.RabbitmqBinding)
+	return &binding.Status, &binding.Status.Conditions, &binding.Status.ObservedGeneration
+}
+
+func (r *ReconcileRabbitmqBinding) syncResource(reqLogger logr.Logger, resource instanceResource) error {
+	return r.reconcileBinding(reqLogger, resource.(*rabbitmqv1.RabbitmqBinding))
+}
+
+func (r *ReconcileRabbitmqBinding) removeResource(reqLogger logr.Logger, resource instanceResource, _ *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client) error {
+	binding := resource.(*rabbitmqv1.RabbitmqBinding)
+	// binding was never created, nothing to clean up
+	if binding.Status.PropertiesKey == "" {
+		return nil
+	}
+	previous := bindingFromStatus(binding)
+	reqLogger.Info("Unbinding " + previous.Destination + " from " + previous.Source + " in " + previous.Vhost + " vhost")
+	return apiClient.DeleteBinding(context.TODO(), previous)
+}
+
+// bindingFromStatus returns binding created on previous reconcile
+func bindingFromStatus(binding *rabbitmqv1.RabbitmqBinding) rabbitmqclient.Binding {
+	return rabbitmqclient.Binding{
+		Vhost:           binding.Status.Vhost,
+		Source:          binding.Status.Source,
+		Destination:     binding.Status.Destination,
+		DestinationType: binding.Status.DestinationType,
+		PropertiesKey:   binding.Status.PropertiesKey,
+	}
+}
+
+// findBinding returns binding with the same routing key and arguments
+func findBinding(bindings []rabbitmqclient.Binding, routingKey string, arguments map[string]interface{}) (rabbitmqclient.Binding, bool) {
+	for _, binding := range bindings {
+		if binding.RoutingKey == routingKey && argumentsEqual(binding.Arguments, arguments) {
+			return binding, true
+		}
+	}
+	return rabbitmqclient.Binding{}, false
+}
+
+func (r *ReconcileRabbitmqBinding) reconcileBinding(reqLogger logr.Logger, binding *rabbitmqv1.RabbitmqBinding) error {
+	bindingCR := rabbitmqclient.Binding{
+		Source:          binding.Spec.Source,
+		Destination:     binding.Spec.Destination,
+		DestinationType: binding.Spec.DestinationType,
+		RoutingKey:      binding.Spec.RoutingKey,
+		Arguments:       binding.Spec.Arguments,
+	}
+	if bindingCR.DestinationType == "" {
+		bindingCR.DestinationType = "queue"
+	}
+	if !containsString(rabbitmqv1.RabbitmqBindingDestinationTypes, bindingCR.DestinationType) {
+		return fmt.Errorf("unsupported destination type %s", bindingCR.DestinationType)
+	}
+	if bindingCR.Source == "" || bindingCR.Destination == "" {
+		return fmt.Errorf("source and destination must be set")
+	}
+
+	if err := r.addFinalizer(binding, RabbitmqBindingFinalizer); err != nil {
+		return err
+	}
+
+	instance, apiClient, _, err := r.getInstanceAPIClient(reqLogger, binding.Namespace, binding.Spec.RabbitmqInstance)
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	bindingCR.Vhost = topologyVhost(instance, binding.Spec.Vhost)
+
+	bindingsRabbit, err := apiClient.ListBindings(ctx, bindingCR.Vhost, bindingCR.Source, bindingCR.Destination, bindingCR.DestinationType)
+	if err != nil {
+		return err
+	}
+
+	bindingRabbit, found := findBinding(bindingsRabbit, bindingCR.RoutingKey, bindingCR.Arguments)
+	if !found {
+		reqLogger.Info("Binding " + bindingCR.Destination + " to " + bindingCR.Source + " in " + bindingCR.Vhost + " vhost")
+		if err := apiClient.AddBinding(ctx, bindingCR); err != nil {
+			return err
+		}
+
+		// properties key of new binding is read back
+		bindingsRabbit, err = apiClient.ListBindings(ctx, bindingCR.Vhost, bindingCR.Source, bindingCR.Destination, bindingCR.DestinationType)
+		if err != nil {
+			return err
+		}
+		bindingRabbit, found = findBinding(bindingsRabbit, bindingCR.RoutingKey, bindingCR.Arguments)
+		if !found {
+			return fmt.Errorf("binding of %s to %s in %s vhost was not created, check that both exist", bindingCR.Destination, bindingCR.Source, bindingCR.Vhost)
+		}
+	}
+
+	// spec was changed, binding created before is removed
+	previous := bindingFromStatus(binding)
+	if previous.PropertiesKey != "" && (previous.Vhost != bindingCR.Vhost || previous.Source != bindingCR.Source || previous.Destination != bindingCR.Destination ||
+		previous.DestinationType != bindingCR.DestinationType || previous.PropertiesKey != bindingRabbit.PropertiesKey) {
+		reqLogger.Info("Unbinding " + previous.Destination + " from " + previous.Source + " in " + previous.Vhost + " vhost")
+		if err := apiClient.DeleteBinding(ctx, previous); err != nil && !rabbitmqclient.IsNotFound(err) {
+			return err
+		}
+	}
+
+	binding.Status.Vhost = bindingCR.Vhost
+	binding.Status.Source = bindingCR.Source
+	binding.Status.Destination = bindingCR.Destination
+	binding.Status.DestinationType = bindingCR.DestinationType
+	binding.Status.PropertiesKey = bindingRabbit.PropertiesKey
+	return nil
+}
@@ -0,0 +1,126 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getsentry/raven-go"
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// RabbitmqExchangeFinalizer removes exchange from rabbitmq before RabbitmqExchange deletion
+const RabbitmqExchangeFinalizer = "rabbitmq.improvado.io/exchange"
+
+// AddRabbitmqExchange creates a new RabbitmqExchange Controller and adds it to the Manager
+func AddRabbitmqExchange(mgr manager.Manager) error {
+	return addRabbitmqExchange(mgr, &ReconcileRabbitmqExchange{ReconcileRabbitmq{client: mgr.GetClient(), scheme: mgr.GetScheme()}})
+}
+
+func addRabbitmqExchange(mgr manager.Manager, reconciler reconcile.Reconciler) error {
+	c, err := controller.New("rabbitmqexchange-controller", mgr, controller.Options{Reconciler: reconciler})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &rabbitmqv1.RabbitmqExchange{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileRabbitmqExchange{}
+
+// ReconcileRabbitmqExchange reconciles a RabbitmqExchange object
+type ReconcileRabbitmqExchange struct {
+	// shares client and management API helpers with Rabbitmq reconciler
+	ReconcileRabbitmq
+}
+
+func rabbitmqExchangeName(exchange *rabbitmqv1.RabbitmqExchange) string {
+	if exchange.Spec.Name != "" {
+		return exchange.Spec.Name
+	}
+	return exchange.Name
+}
+
+// Reconcile declares exchange in rabbitmq
+func (r *ReconcileRabbitmqExchange) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	return r.reconcileInstanceResource(request, "RabbitmqExchange", RabbitmqExchangeFinalizer, r)
+}
+
+func (r *ReconcileRabbitmqExchange) newResource() instanceResource {
+	return &rabbitmqv1.RabbitmqExchange{}
+}
+
+func (r *ReconcileRabbitmqExchange) resourceInstance(resource instanceResource) string {
+	return resource.(*rabbitmqv1.RabbitmqExchange).Spec.RabbitmqInstance
+}
+
+func (r *ReconcileRabbitmqExchange) resourceStatus(resource instanceResource) (interface{}, *[]rabbitmqv1.RabbitmqCondition, *int64) {
+	exchange := resource.(*rabbitmqv1.RabbitmqExchange)
+	return &exchange.Status, &exchange.Status.Conditions, &exchange.Status.ObservedGeneration
+}
+
+func (r *ReconcileRabbitmqExchange) syncResource(reqLogger logr.Logger, resource instanceResource) error {
+	return r.reconcileExchange(reqLogger, resource.(*rabbitmqv1.RabbitmqExchange))
+}
+
+func (r *ReconcileRabbitmqExchange) removeResource(reqLogger logr.Logger, resource instanceResource, instance *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client) error {
+	exchange := resource.(*rabbitmqv1.RabbitmqExchange)
+	vhost := topologyVhost(instance, exchange.Spec.Vhost)
+	reqLogger.Info("Removing " + rabbitmqExchangeName(exchange) + " exchange from " + vhost + " vhost")
+	return apiClient.DeleteExchange(context.TODO(), vhost, rabbitmqExchangeName(exchange))
+}
+
+func (r *ReconcileRabbitmqExchange) reconcileExchange(reqLogger logr.Logger, exchange *rabbitmqv1.RabbitmqExchange) error {
+	if err := r.addFinalizer(exchange, RabbitmqExchangeFinalizer); err != nil {
+		return err
+	}
+
+	instance, apiClient, _, err := r.getInstanceAPIClient(reqLogger, exchange.Namespace, exchange.Spec.RabbitmqInstance)
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+
+	vhost := topologyVhost(instance, exchange.Spec.Vhost)
+	exchangeCR := rabbitmqclient.Exchange{
+		Name:       rabbitmqExchangeName(exchange),
+		Vhost:      vhost,
+		Type:       exchange.Spec.Type,
+		Durable:    topologyDurable(exchange.Spec.Durable),
+		AutoDelete: exchange.Spec.AutoDelete,
+		Internal:   exchange.Spec.Internal,
+		Arguments:  exchange.Spec.Arguments,
+	}
+	if exchangeCR.Type == "" {
+		exchangeCR.Type = "direct"
+	}
+
+	exchangeRabbit, err := apiClient.GetExchange(ctx, vhost, exchangeCR.Name)
+	if rabbitmqclient.IsNotFound(err) {
+		reqLogger.Info("Adding " + exchangeCR.Name + " exchange to " + vhost + " vhost")
+		return apiClient.PutExchange(ctx, exchangeCR)
+	}
+	if err != nil {
+		return err
+	}
+
+	// rabbitmq doesn't allow to redeclare exchange with other properties
+	if exchangeRabbit.Type != exchangeCR.Type || exchangeRabbit.Durable != exchangeCR.Durable || exchangeRabbit.AutoDelete != exchangeCR.AutoDelete ||
+		exchangeRabbit.Internal != exchangeCR.Internal || !argumentsEqual(exchangeRabbit.Arguments, exchangeCR.Arguments) {
+		return fmt.Errorf("exchange %s in %s vhost exists with other properties, they can't be changed in place", exchangeCR.Name, vhost)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getsentry/raven-go"
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// RabbitmqQueueFinalizer removes queue from rabbitmq before RabbitmqQueue deletion
+const RabbitmqQueueFinalizer = "rabbitmq.improvado.io/queue"
+
+// AddRabbitmqQueue creates a new RabbitmqQueue Controller and adds it to the Manager
+func AddRabbitmqQueue(mgr manager.Manager) error {
+	return addRabbitmqQueue(mgr, &ReconcileRabbitmqQueue{ReconcileRabbitmq{client: mgr.GetClient(), scheme: mgr.GetScheme()}})
+}
+
+func addRabbitmqQueue(mgr manager.Manager, reconciler reconcile.Reconciler) error {
+	c, err := controller.New("rabbitmqqueue-controller", mgr, controller.Options{Reconciler: reconciler})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &rabbitmqv1.RabbitmqQueue{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileRabbitmqQueue{}
+
+// ReconcileRabbitmqQueue reconciles a RabbitmqQueue object
+type ReconcileRabbitmqQueue struct {
+	// shares client and management API helpers with Rabbitmq reconciler
+	ReconcileRabbitmq
+}
+
+func rabbitmqQueueName(queue *rabbitmqv1.RabbitmqQueue) string {
+	if queue.Spec.Name != "" {
+		return queue.Spec.Name
+	}
+	return queue.Name
+}
+
+// Reconcile declares queue in rabbitmq
+func (r *ReconcileRabbitmqQueue) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	return r.reconcileInstanceResource(request, "RabbitmqQueue", RabbitmqQueueFinalizer, r)
+}
+
+func (r *ReconcileRabbitmqQueue) newResource() instanceResource {
+	return &rabbitmqv1.RabbitmqQueue{}
+}
+
+func (r *ReconcileRabbitmqQueue) resourceInstance(resource instanceResource) string {
+	return resource.(*rabbitmqv1.RabbitmqQueue).Spec.RabbitmqInstance
+}
+
+func (r *ReconcileRabbitmqQueue) resourceStatus(resource instanceResource) (interface{}, *[]rabbitmqv1.RabbitmqCondition, *int64) {
+	queue := resource.(*rabbitmqv1.RabbitmqQueue)
+	return &queue.Status, &queue.Status.Conditions, &queue.Status.ObservedGeneration
+}
+
+func (r *ReconcileRabbitmqQueue) syncResource(reqLogger logr.Logger, resource instanceResource) error {
+	return r.reconcileQueue(reqLogger, resource.(*rabbitmqv1.RabbitmqQueue))
+}
+
+func (r *ReconcileRabbitmqQueue) removeResource(reqLogger logr.Logger, resource instanceResource, instance *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client) error {
+	queue := resource.(*rabbitmqv1.RabbitmqQueue)
+	vhost := topologyVhost(instance, queue.Spec.Vhost)
+	reqLogger.Info("Removing " + rabbitmqQueueName(queue) + " queue from " + vhost + " vhost")
+	return apiClient.DeleteQueue(context.TODO(), vhost, rabbitmqQueueName(queue))
+}
+
+// queueArguments returns arguments of queue with x-queue-type set from spec type
+func queueArguments(queue *rabbitmqv1.RabbitmqQueue) map[string]interface{} {
+	arguments := map[string]interface{}{}
+	for name, value := range queue.Spec.Arguments {
+		arguments[name] = value
+	}
+	if queue.Spec.Type != "" {
+		arguments["x-queue-type"] = queue.Spec.Type
+	}
+	return arguments
+}
+
+// queueArgumentsFromAPI returns arguments of existing queue to compare with spec. Rabbitmq adds x-queue-type
+// to queues declared without it (default queue type of vhost), it is ignored when spec doesn't set type
+func queueArgumentsFromAPI(arguments map[string]interface{}, argumentsCR map[string]interface{}) map[string]interface{} {
+	if _, ok := argumentsCR["x-queue-type"]; ok {
+		return arguments
+	}
+	if _, ok := arguments["x-queue-type"]; !ok {
+		return arguments
+	}
+	result := map[string]interface{}{}
+	for name, value := range arguments {
+		if name != "x-queue-type" {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+func (r *ReconcileRabbitmqQueue) reconcileQueue(reqLogger logr.Logger, queue *rabbitmqv1.RabbitmqQueue) error {
+	if queue.Spec.Type != "" && !containsString(rabbitmqv1.RabbitmqQueueTypes, queue.Spec.Type) {
+		return fmt.Errorf("unsupported queue type %s", queue.Spec.Type)
+	}
+	if (queue.Spec.Type == "quorum" || queue.Spec.Type == "stream") && (!topologyDurable(queue.Spec.Durable) || queue.Spec.AutoDelete) {
+		return fmt.Errorf("%s queue must be durable and can't be auto-delete", queue.Spec.Type)
+	}
+
+	if err := r.addFinalizer(queue, RabbitmqQueueFinalizer); err != nil {
+		return err
+	}
+
+	instance, apiClient, _, err := r.getInstanceAPIClient(reqLogger, queue.Namespace, queue.Spec.RabbitmqInstance)
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+
+	vhost := topologyVhost(instance, queue.Spec.Vhost)
+	queueCR := rabbitmqclient.Queue{
+		Name:       rabbitmqQueueName(queue),
+		Vhost:      vhost,
+		Durable:    topologyDurable(queue.Spec.Durable),
+		AutoDelete: queue.Spec.AutoDelete,
+		Arguments:  queueArguments(queue),
+	}
+
+	queueRabbit, err := apiClient.GetQueue(ctx, vhost, queueCR.Name)
+	if rabbitmqclient.IsNotFound(err) {
+		reqLogger.Info("Adding " + queueCR.Name + " queue to " + vhost + " vhost")
+		return apiClient.PutQueue(ctx, queueCR)
+	}
+	if err != nil {
+		return err
+	}
+
+	// rabbitmq doesn't allow to redeclare queue with other properties, queue would be recreated with loss of messages
+	if queueRabbit.Durable != queueCR.Durable || queueRabbit.AutoDelete != queueCR.AutoDelete || !argumentsEqual(queueArgumentsFromAPI(queueRabbit.Arguments, queueCR.Arguments), queueCR.Arguments) {
+		return fmt.Errorf("queue %s in %s vhost exists with other properties, they can't be changed in place", queueCR.Name, vhost)
+	}
+	return nil
+}
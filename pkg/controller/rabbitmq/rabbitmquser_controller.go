@@ -0,0 +1,251 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getsentry/raven-go"
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// RabbitmqUserFinalizer removes user from rabbitmq before RabbitmqUser deletion
+const RabbitmqUserFinalizer = "rabbitmq.improvado.io/user"
+
+// AddRabbitmqUser creates a new RabbitmqUser Controller and adds it to the Manager
+func AddRabbitmqUser(mgr manager.Manager) error {
+	return addRabbitmqUser(mgr, &ReconcileRabbitmqUser{ReconcileRabbitmq{client: mgr.GetClient(), scheme: mgr.GetScheme()}})
+}
+
+func addRabbitmqUser(mgr manager.Manager, reconciler reconcile.Reconciler) error {
+	c, err := controller.New("rabbitmquser-controller", mgr, controller.Options{Reconciler: reconciler})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &rabbitmqv1.RabbitmqUser{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	// password can be changed in the owned secret
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &rabbitmqv1.RabbitmqUser{},
+	})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileRabbitmqUser{}
+
+// ReconcileRabbitmqUser reconciles a RabbitmqUser object
+type ReconcileRabbitmqUser struct {
+	// shares client and management API helpers with Rabbitmq reconciler
+	ReconcileRabbitmq
+}
+
+func rabbitmqUserName(user *rabbitmqv1.RabbitmqUser) string {
+	if user.Spec.Username != "" {
+		return user.Spec.Username
+	}
+	return user.Name
+}
+
+func rabbitmqUserSecretName(user *rabbitmqv1.RabbitmqUser) string {
+	if user.Spec.SecretName != "" {
+		return user.Spec.SecretName
+	}
+	return user.Name + "-user-credentials"
+}
+
+// Reconcile creates user in rabbitmq and sets its permissions
+func (r *ReconcileRabbitmqUser) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	return r.reconcileInstanceResource(request, "RabbitmqUser", RabbitmqUserFinalizer, r)
+}
+
+func (r *ReconcileRabbitmqUser) newResource() instanceResource {
+	return &rabbitmqv1.RabbitmqUser{}
+}
+
+func (r *ReconcileRabbitmqUser) resourceInstance(resource instanceResource) string {
+	return resource.(*rabbitmqv1.RabbitmqUser).Spec.RabbitmqInstance
+}
+
+func (r *ReconcileRabbitmqUser) resourceStatus(resource instanceResource) (interface{}, *[]rabbitmqv1.RabbitmqCondition, *int64) {
+	user := resource.(*rabbitmqv1.RabbitmqUser)
+	return &user.Status, &user.Status.Conditions, &user.Status.ObservedGeneration
+}
+
+func (r *ReconcileRabbitmqUser) syncResource(reqLogger logr.Logger, resource instanceResource) error {
+	return r.reconcileUser(reqLogger, resource.(*rabbitmqv1.RabbitmqUser))
+}
+
+func (r *ReconcileRabbitmqUser) removeResource(reqLogger logr.Logger, resource instanceResource, _ *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client) error {
+	user := resource.(*rabbitmqv1.RabbitmqUser)
+	reqLogger.Info("Removing user " + rabbitmqUserName(user))
+	return apiClient.DeleteUser(context.TODO(), rabbitmqUserName(user))
+}
+
+// getInstanceAPIClient returns Rabbitmq by name and client of its management API with service account used by it
+func (r *ReconcileRabbitmq) getInstanceAPIClient(reqLogger logr.Logger, namespace string, name string) (*rabbitmqv1.Rabbitmq, rabbitmqclient.Client, basicAuthCredentials, error) {
+	instance := &rabbitmqv1.Rabbitmq{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, instance)
+	if err != nil {
+		return nil, nil, basicAuthCredentials{}, err
+	}
+	// TLS files are set here too if webhook is not installed
+	instance.Default()
+
+	apiClient, serviceAccount, err := r.getAPIClient(reqLogger, instance, getSecretNames(instance))
+	if err != nil {
+		return nil, nil, basicAuthCredentials{}, err
+	}
+	return instance, apiClient, serviceAccount, nil
+}
+
+// reconcileUserSecret creates secret with generated password once, returns password from existing secret
+func (r *ReconcileRabbitmqUser) reconcileUserSecret(reqLogger logr.Logger, user *rabbitmqv1.RabbitmqUser) (string, error) {
+	secretName := rabbitmqUserSecretName(user)
+	user.Status.SecretName = secretName
+
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: user.Namespace}, secret)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Creating user secret", "Namespace", user.Namespace, "Name", secretName)
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: user.Namespace,
+				Labels:    map[string]string{"rabbitmq.improvado.io/user": user.Name},
+			},
+			Data: map[string][]byte{
+				"username": []byte(rabbitmqUserName(user)),
+				"password": []byte(randomString(30)),
+			},
+		}
+		if err := controllerutil.SetControllerReference(user, secret, r.scheme); err != nil {
+			return "", err
+		}
+		if err := r.client.Create(context.TODO(), secret); err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", err
+	}
+
+	password := string(secret.Data["password"])
+	if password == "" {
+		return "", fmt.Errorf("secret %s has empty password", secretName)
+	}
+	return password, nil
+}
+
+func (r *ReconcileRabbitmqUser) reconcileUser(reqLogger logr.Logger, user *rabbitmqv1.RabbitmqUser) error {
+	if err := r.addFinalizer(user, RabbitmqUserFinalizer); err != nil {
+		return err
+	}
+
+	password, err := r.reconcileUserSecret(reqLogger, user)
+	if err != nil {
+		return err
+	}
+
+	instance, apiClient, serviceAccount, err := r.getInstanceAPIClient(reqLogger, user.Namespace, user.Spec.RabbitmqInstance)
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+
+	userName := rabbitmqUserName(user)
+	if userName == serviceAccount.username {
+		return fmt.Errorf("user %s is the operator service account", userName)
+	}
+
+	if err := r.reconcileUserBinding(reqLogger, user, instance, password); err != nil {
+		return err
+	}
+
+	// update user only if it is missing or changed, so password is not sent on every reconcile
+	userRabbit, err := apiClient.GetUser(ctx, userName)
+	found := err == nil
+	if err != nil && !rabbitmqclient.IsNotFound(err) {
+		return err
+	}
+	tags := rabbitmqclient.Tags(user.Spec.Tags)
+	if tags == nil {
+		tags = rabbitmqclient.Tags{}
+	}
+	if userUpdate, changed := credentialsUser(userName, credentialsEntry{Password: password, Tags: tags}, userRabbit, found); changed {
+		reqLogger.Info("Updating user " + userName + " with tags: " + tags.String())
+		err = apiClient.PutUser(ctx, userUpdate)
+		if err != nil {
+			return err
+		}
+	}
+
+	// vhost permissions
+	if err := syncUserPermissions(ctx, reqLogger, apiClient, userName, user.Spec.Permissions); err != nil {
+		return err
+	}
+
+	// topic permissions
+	if err := syncUserTopicPermissions(ctx, reqLogger, apiClient, userName, user.Spec.TopicPermissions); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// reconcileUserBinding publishes user credentials with the first vhost of its permissions, instance labels
+// make Rabbitmq copy it to bindingNamespaces
+func (r *ReconcileRabbitmqUser) reconcileUserBinding(reqLogger logr.Logger, user *rabbitmqv1.RabbitmqUser, instance *rabbitmqv1.Rabbitmq, password string) error {
+	vhost := instance.DefaultVhost()
+	if len(user.Spec.Permissions) > 0 {
+		vhost = user.Spec.Permissions[0].Vhost
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      userBindingSecretName(user),
+			Namespace: user.Namespace,
+			Labels:    mergeMaps(returnLabels(instance), map[string]string{bindingLabel: "true", "rabbitmq.improvado.io/user": user.Name}),
+		},
+		Type: bindingSecretType,
+		Data: bindingSecretData(instance, rabbitmqUserName(user), password, vhost),
+	}
+	return r.reconcileBindingSecret(reqLogger, user, secret)
+}
+
+// listManagedUsernames returns users of the instance managed by RabbitmqUser resources
+func (r *ReconcileRabbitmq) listManagedUsernames(cr *rabbitmqv1.Rabbitmq) ([]string, error) {
+	users := &rabbitmqv1.RabbitmqUserList{}
+	if err := r.client.List(context.TODO(), client.InNamespace(cr.Namespace), users); err != nil {
+		return nil, err
+	}
+
+	var usernames []string
+	for i := range users.Items {
+		if users.Items[i].Spec.RabbitmqInstance == cr.Name {
+			usernames = append(usernames, rabbitmqUserName(&users.Items[i]))
+		}
+	}
+	return usernames, nil
+}
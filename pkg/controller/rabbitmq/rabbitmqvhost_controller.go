@@ -0,0 +1,162 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/getsentry/raven-go"
+	"github.com/go-logr/logr"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	"github.com/tekliner/rabbitmq-operator/pkg/rabbitmqclient"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// RabbitmqVhostFinalizer removes vhost from rabbitmq before RabbitmqVhost deletion
+const RabbitmqVhostFinalizer = "rabbitmq.improvado.io/vhost"
+
+// vhost limit names in /api/vhost-limits
+const (
+	rabbitmqVhostLimitMaxConnections = "max-connections"
+	rabbitmqVhostLimitMaxQueues      = "max-queues"
+)
+
+// AddRabbitmqVhost creates a new RabbitmqVhost Controller and adds it to the Manager
+func AddRabbitmqVhost(mgr manager.Manager) error {
+	return addRabbitmqVhost(mgr, &ReconcileRabbitmqVhost{ReconcileRabbitmq{client: mgr.GetClient(), scheme: mgr.GetScheme()}})
+}
+
+func addRabbitmqVhost(mgr manager.Manager, reconciler reconcile.Reconciler) error {
+	c, err := controller.New("rabbitmqvhost-controller", mgr, controller.Options{Reconciler: reconciler})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &rabbitmqv1.RabbitmqVhost{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		raven.CaptureErrorAndWait(err, nil)
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileRabbitmqVhost{}
+
+// ReconcileRabbitmqVhost reconciles a RabbitmqVhost object
+type ReconcileRabbitmqVhost struct {
+	// shares client and management API helpers with Rabbitmq reconciler
+	ReconcileRabbitmq
+}
+
+func rabbitmqVhostName(vhost *rabbitmqv1.RabbitmqVhost) string {
+	if vhost.Spec.Name != "" {
+		return vhost.Spec.Name
+	}
+	return vhost.Name
+}
+
+// Reconcile creates vhost in rabbitmq and sets its limits
+func (r *ReconcileRabbitmqVhost) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	return r.reconcileInstanceResource(request, "RabbitmqVhost", RabbitmqVhostFinalizer, r)
+}
+
+func (r *ReconcileRabbitmqVhost) newResource() instanceResource {
+	return &rabbitmqv1.RabbitmqVhost{}
+}
+
+func (r *ReconcileRabbitmqVhost) resourceInstance(resource instanceResource) string {
+	return resource.(*rabbitmqv1.RabbitmqVhost).Spec.RabbitmqInstance
+}
+
+func (r *ReconcileRabbitmqVhost) resourceStatus(resource instanceResource) (interface{}, *[]rabbitmqv1.RabbitmqCondition, *int64) {
+	vhost := resource.(*rabbitmqv1.RabbitmqVhost)
+	return &vhost.Status, &vhost.Status.Conditions, &vhost.Status.ObservedGeneration
+}
+
+func (r *ReconcileRabbitmqVhost) syncResource(reqLogger logr.Logger, resource instanceResource) error {
+	return r.reconcileVhost(reqLogger, resource.(*rabbitmqv1.RabbitmqVhost))
+}
+
+func (r *ReconcileRabbitmqVhost) removeResource(reqLogger logr.Logger, resource instanceResource, instance *rabbitmqv1.Rabbitmq, apiClient rabbitmqclient.Client) error {
+	vhost := resource.(*rabbitmqv1.RabbitmqVhost)
+	// default vhost of the instance is never removed
+	if rabbitmqVhostName(vhost) == instance.DefaultVhost() {
+		return nil
+	}
+	reqLogger.Info("Removing " + rabbitmqVhostName(vhost) + " vhost")
+	return apiClient.DeleteVhost(context.TODO(), rabbitmqVhostName(vhost))
+}
+
+func (r *ReconcileRabbitmqVhost) reconcileVhost(reqLogger logr.Logger, vhost *rabbitmqv1.RabbitmqVhost) error {
+	if vhost.Spec.DefaultQueueType != "" && !containsString(rabbitmqv1.RabbitmqQueueTypes, vhost.Spec.DefaultQueueType) {
+		return fmt.Errorf("unsupported default queue type %s", vhost.Spec.DefaultQueueType)
+	}
+
+	if err := r.addFinalizer(vhost, RabbitmqVhostFinalizer); err != nil {
+		return err
+	}
+
+	_, apiClient, _, err := r.getInstanceAPIClient(reqLogger, vhost.Namespace, vhost.Spec.RabbitmqInstance)
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+
+	vhostName := rabbitmqVhostName(vhost)
+	tags := rabbitmqclient.Tags(vhost.Spec.Tags)
+	if tags == nil {
+		tags = rabbitmqclient.Tags{}
+	}
+
+	// PUT of existing vhost updates description, tags and default queue type
+	vhostRabbit, err := apiClient.GetVhost(ctx, vhostName)
+	found := err == nil
+	if err != nil && !rabbitmqclient.IsNotFound(err) {
+		return err
+	}
+	if !found || vhostRabbit.Description != vhost.Spec.Description || !reflect.DeepEqual(vhostRabbit.Tags, tags) ||
+		(vhost.Spec.DefaultQueueType != "" && vhostRabbit.DefaultQueueType != vhost.Spec.DefaultQueueType) {
+		reqLogger.Info("Updating " + vhostName + " vhost")
+		err = apiClient.PutVhost(ctx, rabbitmqclient.Vhost{
+			Name:             vhostName,
+			Description:      vhost.Spec.Description,
+			Tags:             tags,
+			DefaultQueueType: vhost.Spec.DefaultQueueType,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	limitsRabbit, err := apiClient.GetVhostLimits(ctx, vhostName)
+	if err != nil {
+		return err
+	}
+
+	limitsCR := map[string]*int64{
+		rabbitmqVhostLimitMaxConnections: vhost.Spec.Limits.MaxConnections,
+		rabbitmqVhostLimitMaxQueues:      vhost.Spec.Limits.MaxQueues,
+	}
+	for limitName, limitCR := range limitsCR {
+		limitRabbit, limitFound := limitsRabbit[limitName]
+		switch {
+		case limitCR == nil && limitFound:
+			reqLogger.Info("Removing " + limitName + " limit of " + vhostName + " vhost")
+			err = apiClient.DeleteVhostLimit(ctx, vhostName, limitName)
+		case limitCR != nil && (!limitFound || limitRabbit != *limitCR):
+			reqLogger.Info("Setting " + limitName + " limit of " + vhostName + " vhost")
+			err = apiClient.PutVhostLimit(ctx, vhostName, limitName, *limitCR)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+// Package rabbitmqclient is a client of RabbitMQ management HTTP API
+package rabbitmqclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout of a request when context has no deadline
+const DefaultTimeout = 30 * time.Second
+
+// Client is RabbitMQ management API used by reconcilers
+type Client interface {
+	Overview(ctx context.Context) (Overview, error)
+	ListNodes(ctx context.Context) ([]Node, error)
+	HealthCheck(ctx context.Context, check string) error
+
+	ListVhosts(ctx context.Context) ([]Vhost, error)
+	GetVhost(ctx context.Context, name string) (Vhost, error)
+	PutVhost(ctx context.Context, vhost Vhost) error
+	DeleteVhost(ctx context.Context, name string) error
+	GetVhostLimits(ctx context.Context, vhost string) (map[string]int64, error)
+	PutVhostLimit(ctx context.Context, vhost string, limit string, value int64) error
+	DeleteVhostLimit(ctx context.Context, vhost string, limit string) error
+
+	ListUsers(ctx context.Context) ([]User, error)
+	GetUser(ctx context.Context, name string) (User, error)
+	PutUser(ctx context.Context, user User) error
+	DeleteUser(ctx context.Context, name string) error
+	DeleteUsers(ctx context.Context, names []string) error
+
+	ListUserPermissions(ctx context.Context, user string) ([]Permission, error)
+	PutPermission(ctx context.Context, permission Permission) error
+	DeletePermission(ctx context.Context, vhost string, user string) error
+	ListUserTopicPermissions(ctx context.Context, user string) ([]TopicPermission, error)
+	PutTopicPermission(ctx context.Context, permission TopicPermission) error
+	DeleteTopicPermissions(ctx context.Context, vhost string, user string) error
+
+	ListPolicies(ctx context.Context) ([]Policy, error)
+	PutPolicy(ctx context.Context, policy Policy) error
+	DeletePolicy(ctx context.Context, vhost string, name string) error
+	ListOperatorPolicies(ctx context.Context) ([]Policy, error)
+	PutOperatorPolicy(ctx context.Context, policy Policy) error
+	DeleteOperatorPolicy(ctx context.Context, vhost string, name string) error
+
+	GetExchange(ctx context.Context, vhost string, name string) (Exchange, error)
+	PutExchange(ctx context.Context, exchange Exchange) error
+	DeleteExchange(ctx context.Context, vhost string, name string) error
+	GetQueue(ctx context.Context, vhost string, name string) (Queue, error)
+	PutQueue(ctx context.Context, queue Queue) error
+	DeleteQueue(ctx context.Context, vhost string, name string) error
+	ListBindings(ctx context.Context, vhost string, source string, destination string, destinationType string) ([]Binding, error)
+	AddBinding(ctx context.Context, binding Binding) error
+	DeleteBinding(ctx context.Context, binding Binding) error
+
+	ListParameters(ctx context.Context, component string) ([]Parameter, error)
+	PutParameter(ctx context.Context, parameter Parameter) error
+	DeleteParameter(ctx context.Context, component string, vhost string, name string) error
+
+	GetDefinitions(ctx context.Context) (json.RawMessage, error)
+	PostDefinitions(ctx context.Context, definitions json.RawMessage) error
+}
+
+// Options of client
+type Options struct {
+	// management API address like http://rabbitmq-api.default:15672
+	BaseURL  string
+	Username string
+	Password string
+
+	// TLS of https BaseURL, system roots are used if empty
+	TLSConfig *tls.Config
+
+	// custom http client, connections of default one are shared between all clients without TLSConfig
+	HTTPClient *http.Client
+}
+
+var defaultHTTPClient = &http.Client{
+	Transport: newTransport(nil),
+}
+
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+type client struct {
+	baseURL    *url.URL
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+var _ Client = &client{}
+
+// New creates management API client
+func New(options Options) (Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(options.BaseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("management API URL %q must start with http:// or https://", options.BaseURL)
+	}
+
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+		if options.TLSConfig != nil {
+			httpClient = &http.Client{Transport: newTransport(options.TLSConfig)}
+		}
+	}
+
+	return &client{
+		baseURL:    baseURL,
+		username:   options.Username,
+		password:   options.Password,
+		httpClient: httpClient,
+	}, nil
+}
+
+// EscapeVhost escapes vhost name as one path segment, "/" becomes "%2F" and "%2f" is a vhost named "%2f"
+func EscapeVhost(vhost string) string {
+	return url.PathEscape(vhost)
+}
+
+// apiPath joins escaped segments to /api path
+func apiPath(segments ...string) string {
+	return "/api/" + strings.Join(segments, "/")
+}
+
+// do sends request, decodes JSON response to result if it is not nil and returns *Error on non 2xx status
+func (c *client) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	// path is already escaped, RawPath keeps %2F in vhost names
+	requestURL := *c.baseURL
+	requestURL.RawPath = c.baseURL.EscapedPath() + path
+	requestURL.Path, _ = url.PathUnescape(requestURL.RawPath)
+
+	request, err := http.NewRequest(method, requestURL.String(), bodyReader)
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.SetBasicAuth(c.username, c.password)
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		apiErr := &Error{Method: method, Path: path, StatusCode: response.StatusCode}
+		// error body is {"error": "...", "reason": "..."}, plain text for some proxies
+		if json.Unmarshal(data, apiErr) != nil {
+			apiErr.Reason = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (c *client) get(ctx context.Context, path string, result interface{}) error {
+	return c.do(ctx, http.MethodGet, path, nil, result)
+}
+
+func (c *client) put(ctx context.Context, path string, body interface{}) error {
+	return c.do(ctx, http.MethodPut, path, body, nil)
+}
+
+func (c *client) post(ctx context.Context, path string, body interface{}) error {
+	return c.do(ctx, http.MethodPost, path, body, nil)
+}
+
+func (c *client) delete(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
@@ -0,0 +1,117 @@
+package rabbitmqclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	client, err := New(Options{BaseURL: server.URL, Username: "operator", Password: "secret"})
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return client, server
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		notFound     bool
+		unauthorized bool
+		reason       string
+	}{
+		{name: "not found", status: http.StatusNotFound, body: `{"error":"Object Not Found","reason":"Not Found"}`, notFound: true, reason: "Not Found"},
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `{"error":"not_authorised","reason":"Login failed"}`, unauthorized: true, reason: "Login failed"},
+		{name: "plain text body", status: http.StatusBadGateway, body: "bad gateway\n", reason: "bad gateway"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			})
+			defer server.Close()
+
+			_, err := client.GetVhost(context.Background(), "orders")
+			apiErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("error = %#v, want *Error", err)
+			}
+			if apiErr.StatusCode != test.status || apiErr.Reason != test.reason {
+				t.Errorf("status = %d reason = %q, want %d %q", apiErr.StatusCode, apiErr.Reason, test.status, test.reason)
+			}
+			if IsNotFound(err) != test.notFound {
+				t.Errorf("IsNotFound = %v, want %v", IsNotFound(err), test.notFound)
+			}
+			if IsUnauthorized(err) != test.unauthorized {
+				t.Errorf("IsUnauthorized = %v, want %v", IsUnauthorized(err), test.unauthorized)
+			}
+		})
+	}
+}
+
+func TestVhostEscaping(t *testing.T) {
+	tests := []struct {
+		vhost string
+		path  string
+	}{
+		{vhost: "/", path: "/api/vhosts/%2F"},
+		{vhost: "%2f", path: "/api/vhosts/%252f"},
+		{vhost: "team a", path: "/api/vhosts/team%20a"},
+		{vhost: "a/b?c#d", path: "/api/vhosts/a%2Fb%3Fc%23d"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.vhost, func(t *testing.T) {
+			var requested string
+			client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requested = r.URL.EscapedPath()
+				if username, password, _ := r.BasicAuth(); username != "operator" || password != "secret" {
+					t.Errorf("basic auth = %s:%s", username, password)
+				}
+				w.Write([]byte(`{}`))
+			})
+			defer server.Close()
+
+			if _, err := client.GetVhost(context.Background(), test.vhost); err != nil {
+				t.Fatal(err)
+			}
+			if requested != test.path {
+				t.Errorf("path = %s, want %s", requested, test.path)
+			}
+			if EscapeVhost(test.vhost) != test.path[len("/api/vhosts/"):] {
+				t.Errorf("EscapeVhost(%q) = %s", test.vhost, EscapeVhost(test.vhost))
+			}
+		})
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := client.Overview(ctx)
+	if err == nil {
+		t.Fatal("request without response succeeded")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("context error = %v", ctx.Err())
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("request returned after %s, deadline was not applied", elapsed)
+	}
+}
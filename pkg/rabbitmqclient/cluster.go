@@ -0,0 +1,65 @@
+package rabbitmqclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// Overview returns versions and cluster name
+func (c *client) Overview(ctx context.Context) (Overview, error) {
+	var overview Overview
+	err := c.get(ctx, apiPath("overview"), &overview)
+	return overview, err
+}
+
+// ListNodes returns cluster members
+func (c *client) ListNodes(ctx context.Context) ([]Node, error) {
+	var nodes []Node
+	err := c.get(ctx, apiPath("nodes"), &nodes)
+	return nodes, err
+}
+
+// Health checks of the node serving the request
+const (
+	// HealthCheckNodeIsQuorumCritical fails if quorum queues would lose majority without the node
+	HealthCheckNodeIsQuorumCritical = "node-is-quorum-critical"
+	// HealthCheckNodeIsMirrorSyncCritical fails if classic mirrored queues have no synchronised mirrors on other nodes
+	HealthCheckNodeIsMirrorSyncCritical = "node-is-mirror-sync-critical"
+	// HealthCheckAlarms fails if any node has a resource alarm
+	HealthCheckAlarms = "alarms"
+)
+
+// HealthCheck runs /api/health/checks/<check> on the node serving the request, failed check is *Error with 503 status
+func (c *client) HealthCheck(ctx context.Context, check string) error {
+	return c.get(ctx, apiPath("health", "checks", check), nil)
+}
+
+// GetDefinitions exports vhosts, users, permissions, policies, queues, exchanges and bindings
+func (c *client) GetDefinitions(ctx context.Context) (json.RawMessage, error) {
+	var definitions json.RawMessage
+	err := c.get(ctx, apiPath("definitions"), &definitions)
+	return definitions, err
+}
+
+// PostDefinitions imports definitions, existing objects are kept
+func (c *client) PostDefinitions(ctx context.Context, definitions json.RawMessage) error {
+	return c.post(ctx, apiPath("definitions"), definitions)
+}
+
+// ListParameters returns parameters of component in all vhosts
+func (c *client) ListParameters(ctx context.Context, component string) ([]Parameter, error) {
+	var parameters []Parameter
+	err := c.get(ctx, apiPath("parameters", url.PathEscape(component)), &parameters)
+	return parameters, err
+}
+
+// PutParameter creates or updates parameter
+func (c *client) PutParameter(ctx context.Context, parameter Parameter) error {
+	return c.put(ctx, apiPath("parameters", url.PathEscape(parameter.Component), EscapeVhost(parameter.Vhost), url.PathEscape(parameter.Name)), parameter)
+}
+
+// DeleteParameter removes parameter
+func (c *client) DeleteParameter(ctx context.Context, component string, vhost string, name string) error {
+	return c.delete(ctx, apiPath("parameters", url.PathEscape(component), EscapeVhost(vhost), url.PathEscape(name)))
+}
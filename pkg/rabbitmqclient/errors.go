@@ -0,0 +1,50 @@
+package rabbitmqclient
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is a non 2xx response of management API
+type Error struct {
+	Method     string `json:"-"`
+	Path       string `json:"-"`
+	StatusCode int    `json:"-"`
+
+	// error and reason fields of response body
+	Type   string `json:"error"`
+	Reason string `json:"reason"`
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+	return message
+}
+
+func hasStatus(err error, statusCode int) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == statusCode
+}
+
+// IsNotFound is true when object doesn't exist
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized is true when credentials are wrong
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden is true when user has no permissions for the request
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsBadRequest is true when request was rejected, like redeclaration of queue with other arguments
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
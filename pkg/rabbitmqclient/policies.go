@@ -0,0 +1,50 @@
+package rabbitmqclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// ListPolicies returns policies of all vhosts
+func (c *client) ListPolicies(ctx context.Context) ([]Policy, error) {
+	var policies []Policy
+	err := c.get(ctx, apiPath("policies"), &policies)
+	return policies, err
+}
+
+// PutPolicy creates or updates policy
+func (c *client) PutPolicy(ctx context.Context, policy Policy) error {
+	return c.put(ctx, apiPath("policies", EscapeVhost(policy.Vhost), url.PathEscape(policy.Name)), policyBody(policy))
+}
+
+// DeletePolicy removes policy
+func (c *client) DeletePolicy(ctx context.Context, vhost string, name string) error {
+	return c.delete(ctx, apiPath("policies", EscapeVhost(vhost), url.PathEscape(name)))
+}
+
+// ListOperatorPolicies returns operator policies of all vhosts
+func (c *client) ListOperatorPolicies(ctx context.Context) ([]Policy, error) {
+	var policies []Policy
+	err := c.get(ctx, apiPath("operator-policies"), &policies)
+	return policies, err
+}
+
+// PutOperatorPolicy creates or updates operator policy
+func (c *client) PutOperatorPolicy(ctx context.Context, policy Policy) error {
+	return c.put(ctx, apiPath("operator-policies", EscapeVhost(policy.Vhost), url.PathEscape(policy.Name)), policyBody(policy))
+}
+
+// DeleteOperatorPolicy removes operator policy
+func (c *client) DeleteOperatorPolicy(ctx context.Context, vhost string, name string) error {
+	return c.delete(ctx, apiPath("operator-policies", EscapeVhost(vhost), url.PathEscape(name)))
+}
+
+// policyBody vhost and name are in URL
+func policyBody(policy Policy) Policy {
+	return Policy{
+		Pattern:    policy.Pattern,
+		Definition: policy.Definition,
+		Priority:   policy.Priority,
+		ApplyTo:    policy.ApplyTo,
+	}
+}
@@ -0,0 +1,80 @@
+package rabbitmqclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// GetExchange returns exchange, IsNotFound error if it doesn't exist
+func (c *client) GetExchange(ctx context.Context, vhost string, name string) (Exchange, error) {
+	var exchange Exchange
+	err := c.get(ctx, apiPath("exchanges", EscapeVhost(vhost), url.PathEscape(name)), &exchange)
+	return exchange, err
+}
+
+// PutExchange declares exchange, IsBadRequest error if it exists with other properties
+func (c *client) PutExchange(ctx context.Context, exchange Exchange) error {
+	return c.put(ctx, apiPath("exchanges", EscapeVhost(exchange.Vhost), url.PathEscape(exchange.Name)), Exchange{
+		Type:       exchange.Type,
+		Durable:    exchange.Durable,
+		AutoDelete: exchange.AutoDelete,
+		Internal:   exchange.Internal,
+		Arguments:  exchange.Arguments,
+	})
+}
+
+// DeleteExchange removes exchange
+func (c *client) DeleteExchange(ctx context.Context, vhost string, name string) error {
+	return c.delete(ctx, apiPath("exchanges", EscapeVhost(vhost), url.PathEscape(name)))
+}
+
+// GetQueue returns queue, IsNotFound error if it doesn't exist
+func (c *client) GetQueue(ctx context.Context, vhost string, name string) (Queue, error) {
+	var queue Queue
+	err := c.get(ctx, apiPath("queues", EscapeVhost(vhost), url.PathEscape(name)), &queue)
+	return queue, err
+}
+
+// PutQueue declares queue, IsBadRequest error if it exists with other properties
+func (c *client) PutQueue(ctx context.Context, queue Queue) error {
+	return c.put(ctx, apiPath("queues", EscapeVhost(queue.Vhost), url.PathEscape(queue.Name)), Queue{
+		Durable:    queue.Durable,
+		AutoDelete: queue.AutoDelete,
+		Arguments:  queue.Arguments,
+	})
+}
+
+// DeleteQueue removes queue with its messages
+func (c *client) DeleteQueue(ctx context.Context, vhost string, name string) error {
+	return c.delete(ctx, apiPath("queues", EscapeVhost(vhost), url.PathEscape(name)))
+}
+
+// bindingPath returns path of bindings between source exchange and destination queue or exchange
+func bindingPath(vhost string, source string, destination string, destinationType string) string {
+	destinationPath := "q"
+	if destinationType == "exchange" {
+		destinationPath = "e"
+	}
+	return apiPath("bindings", EscapeVhost(vhost), "e", url.PathEscape(source), destinationPath, url.PathEscape(destination))
+}
+
+// ListBindings returns bindings between source exchange and destination, destination type is queue or exchange
+func (c *client) ListBindings(ctx context.Context, vhost string, source string, destination string, destinationType string) ([]Binding, error) {
+	var bindings []Binding
+	err := c.get(ctx, bindingPath(vhost, source, destination, destinationType), &bindings)
+	return bindings, err
+}
+
+// AddBinding binds destination to source exchange
+func (c *client) AddBinding(ctx context.Context, binding Binding) error {
+	return c.post(ctx, bindingPath(binding.Vhost, binding.Source, binding.Destination, binding.DestinationType), Binding{
+		RoutingKey: binding.RoutingKey,
+		Arguments:  binding.Arguments,
+	})
+}
+
+// DeleteBinding removes binding by its properties key
+func (c *client) DeleteBinding(ctx context.Context, binding Binding) error {
+	// properties key is already escaped by rabbitmq
+	return c.delete(ctx, bindingPath(binding.Vhost, binding.Source, binding.Destination, binding.DestinationType)+"/"+binding.PropertiesKey)
+}
@@ -0,0 +1,154 @@
+package rabbitmqclient
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Tags of users and vhosts, comma separated string in requests, rabbitmq 3.9+ returns array
+type Tags []string
+
+// MarshalJSON encodes tags as comma separated string supported by all versions
+func (t Tags) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(t, ","))
+}
+
+// UnmarshalJSON decodes array or comma separated string
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	var tagsList []string
+	if err := json.Unmarshal(data, &tagsList); err == nil {
+		*t = tagsList
+		return nil
+	}
+
+	var tagsString string
+	if err := json.Unmarshal(data, &tagsString); err != nil {
+		return err
+	}
+	*t = Tags{}
+	for _, tag := range strings.Split(tagsString, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			*t = append(*t, tag)
+		}
+	}
+	return nil
+}
+
+func (t Tags) String() string {
+	return strings.Join(t, ",")
+}
+
+// Overview of cluster from /api/overview
+type Overview struct {
+	ClusterName     string `json:"cluster_name"`
+	RabbitmqVersion string `json:"rabbitmq_version"`
+	ErlangVersion   string `json:"erlang_version"`
+}
+
+// Node of cluster from /api/nodes
+type Node struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Running    bool     `json:"running"`
+	Partitions []string `json:"partitions"`
+	// node is in maintenance mode after rabbitmq-upgrade drain
+	BeingDrained bool `json:"being_drained"`
+}
+
+// Vhost from /api/vhosts
+type Vhost struct {
+	Name             string `json:"name,omitempty"`
+	Description      string `json:"description"`
+	Tags             Tags   `json:"tags"`
+	DefaultQueueType string `json:"default_queue_type,omitempty"`
+}
+
+type vhostLimits struct {
+	Vhost string           `json:"vhost"`
+	Value map[string]int64 `json:"value"`
+}
+
+type vhostLimitValue struct {
+	Value int64 `json:"value"`
+}
+
+// User from /api/users, Password is only sent, PasswordHash is only received unless set
+type User struct {
+	Name             string `json:"name"`
+	Password         string `json:"password,omitempty"`
+	PasswordHash     string `json:"password_hash,omitempty"`
+	HashingAlgorithm string `json:"hashing_algorithm,omitempty"`
+	Tags             Tags   `json:"tags"`
+}
+
+type usersList struct {
+	Users []string `json:"users"`
+}
+
+// Permission of user in vhost
+type Permission struct {
+	User      string `json:"user,omitempty"`
+	Vhost     string `json:"vhost,omitempty"`
+	Configure string `json:"configure"`
+	Write     string `json:"write"`
+	Read      string `json:"read"`
+}
+
+// TopicPermission of user for topic exchange in vhost
+type TopicPermission struct {
+	User     string `json:"user,omitempty"`
+	Vhost    string `json:"vhost,omitempty"`
+	Exchange string `json:"exchange"`
+	Write    string `json:"write"`
+	Read     string `json:"read"`
+}
+
+// Policy or operator policy
+type Policy struct {
+	Vhost      string                 `json:"vhost,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	Pattern    string                 `json:"pattern"`
+	Definition map[string]interface{} `json:"definition"`
+	Priority   int64                  `json:"priority"`
+	ApplyTo    string                 `json:"apply-to,omitempty"`
+}
+
+// Exchange from /api/exchanges
+type Exchange struct {
+	Name       string                 `json:"name,omitempty"`
+	Vhost      string                 `json:"vhost,omitempty"`
+	Type       string                 `json:"type"`
+	Durable    bool                   `json:"durable"`
+	AutoDelete bool                   `json:"auto_delete"`
+	Internal   bool                   `json:"internal"`
+	Arguments  map[string]interface{} `json:"arguments"`
+}
+
+// Queue from /api/queues
+type Queue struct {
+	Name       string                 `json:"name,omitempty"`
+	Vhost      string                 `json:"vhost,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Durable    bool                   `json:"durable"`
+	AutoDelete bool                   `json:"auto_delete"`
+	Arguments  map[string]interface{} `json:"arguments"`
+}
+
+// Binding of queue or exchange to source exchange
+type Binding struct {
+	Source          string                 `json:"source,omitempty"`
+	Vhost           string                 `json:"vhost,omitempty"`
+	Destination     string                 `json:"destination,omitempty"`
+	DestinationType string                 `json:"destination_type,omitempty"`
+	RoutingKey      string                 `json:"routing_key"`
+	Arguments       map[string]interface{} `json:"arguments"`
+	PropertiesKey   string                 `json:"properties_key,omitempty"`
+}
+
+// Parameter of component like federation-upstream or shovel
+type Parameter struct {
+	Component string          `json:"component"`
+	Vhost     string          `json:"vhost"`
+	Name      string          `json:"name"`
+	Value     json.RawMessage `json:"value"`
+}
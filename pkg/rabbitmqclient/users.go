@@ -0,0 +1,82 @@
+package rabbitmqclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// ListUsers returns all users
+func (c *client) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	err := c.get(ctx, apiPath("users"), &users)
+	return users, err
+}
+
+// GetUser returns user with password hash, IsNotFound error if it doesn't exist
+func (c *client) GetUser(ctx context.Context, name string) (User, error) {
+	var user User
+	err := c.get(ctx, apiPath("users", url.PathEscape(name)), &user)
+	return user, err
+}
+
+// PutUser creates user or updates its password and tags
+func (c *client) PutUser(ctx context.Context, user User) error {
+	return c.put(ctx, apiPath("users", url.PathEscape(user.Name)), User{
+		Password:         user.Password,
+		PasswordHash:     user.PasswordHash,
+		HashingAlgorithm: user.HashingAlgorithm,
+		Tags:             user.Tags,
+	})
+}
+
+// DeleteUser removes user
+func (c *client) DeleteUser(ctx context.Context, name string) error {
+	return c.delete(ctx, apiPath("users", url.PathEscape(name)))
+}
+
+// DeleteUsers removes users with one request
+func (c *client) DeleteUsers(ctx context.Context, names []string) error {
+	return c.post(ctx, apiPath("users", "bulk-delete"), usersList{Users: names})
+}
+
+// ListUserPermissions returns permissions of user in all vhosts
+func (c *client) ListUserPermissions(ctx context.Context, user string) ([]Permission, error) {
+	var permissions []Permission
+	err := c.get(ctx, apiPath("users", url.PathEscape(user), "permissions"), &permissions)
+	return permissions, err
+}
+
+// PutPermission sets permissions of user in vhost
+func (c *client) PutPermission(ctx context.Context, permission Permission) error {
+	return c.put(ctx, apiPath("permissions", EscapeVhost(permission.Vhost), url.PathEscape(permission.User)), Permission{
+		Configure: permission.Configure,
+		Write:     permission.Write,
+		Read:      permission.Read,
+	})
+}
+
+// DeletePermission removes permissions of user in vhost
+func (c *client) DeletePermission(ctx context.Context, vhost string, user string) error {
+	return c.delete(ctx, apiPath("permissions", EscapeVhost(vhost), url.PathEscape(user)))
+}
+
+// ListUserTopicPermissions returns topic permissions of user in all vhosts
+func (c *client) ListUserTopicPermissions(ctx context.Context, user string) ([]TopicPermission, error) {
+	var permissions []TopicPermission
+	err := c.get(ctx, apiPath("users", url.PathEscape(user), "topic-permissions"), &permissions)
+	return permissions, err
+}
+
+// PutTopicPermission sets topic permissions of user for exchange in vhost
+func (c *client) PutTopicPermission(ctx context.Context, permission TopicPermission) error {
+	return c.put(ctx, apiPath("topic-permissions", EscapeVhost(permission.Vhost), url.PathEscape(permission.User)), TopicPermission{
+		Exchange: permission.Exchange,
+		Write:    permission.Write,
+		Read:     permission.Read,
+	})
+}
+
+// DeleteTopicPermissions removes topic permissions of user for all exchanges of vhost
+func (c *client) DeleteTopicPermissions(ctx context.Context, vhost string, user string) error {
+	return c.delete(ctx, apiPath("topic-permissions", EscapeVhost(vhost), url.PathEscape(user)))
+}
@@ -0,0 +1,60 @@
+package rabbitmqclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// ListVhosts returns all vhosts
+func (c *client) ListVhosts(ctx context.Context) ([]Vhost, error) {
+	var vhosts []Vhost
+	err := c.get(ctx, apiPath("vhosts"), &vhosts)
+	return vhosts, err
+}
+
+// GetVhost returns vhost, IsNotFound error if it doesn't exist
+func (c *client) GetVhost(ctx context.Context, name string) (Vhost, error) {
+	var vhost Vhost
+	err := c.get(ctx, apiPath("vhosts", EscapeVhost(name)), &vhost)
+	return vhost, err
+}
+
+// PutVhost creates vhost or updates description, tags and default queue type
+func (c *client) PutVhost(ctx context.Context, vhost Vhost) error {
+	return c.put(ctx, apiPath("vhosts", EscapeVhost(vhost.Name)), Vhost{
+		Description:      vhost.Description,
+		Tags:             vhost.Tags,
+		DefaultQueueType: vhost.DefaultQueueType,
+	})
+}
+
+// DeleteVhost removes vhost with all its queues and exchanges
+func (c *client) DeleteVhost(ctx context.Context, name string) error {
+	return c.delete(ctx, apiPath("vhosts", EscapeVhost(name)))
+}
+
+// GetVhostLimits returns limits of vhost by name, like max-connections
+func (c *client) GetVhostLimits(ctx context.Context, vhost string) (map[string]int64, error) {
+	var limits []vhostLimits
+	if err := c.get(ctx, apiPath("vhost-limits", EscapeVhost(vhost)), &limits); err != nil {
+		return nil, err
+	}
+
+	values := map[string]int64{}
+	for _, limit := range limits {
+		for name, value := range limit.Value {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// PutVhostLimit sets limit of vhost
+func (c *client) PutVhostLimit(ctx context.Context, vhost string, limit string, value int64) error {
+	return c.put(ctx, apiPath("vhost-limits", EscapeVhost(vhost), url.PathEscape(limit)), vhostLimitValue{Value: value})
+}
+
+// DeleteVhostLimit removes limit of vhost
+func (c *client) DeleteVhostLimit(ctx context.Context, vhost string, limit string) error {
+	return c.delete(ctx, apiPath("vhost-limits", EscapeVhost(vhost), url.PathEscape(limit)))
+}
@@ -0,0 +1,10 @@
+package webhook
+
+import (
+	"github.com/tekliner/rabbitmq-operator/pkg/webhook/rabbitmq"
+)
+
+func init() {
+	// AddToManagerFuncs is a list of functions to create webhooks and add them to a manager.
+	AddToManagerFuncs = append(AddToManagerFuncs, rabbitmq.NewDefaultingWebhook, rabbitmq.NewValidatingWebhook)
+}
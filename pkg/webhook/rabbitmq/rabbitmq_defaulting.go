@@ -0,0 +1,63 @@
+package rabbitmq
+
+import (
+	"context"
+	"net/http"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/types"
+)
+
+// NewDefaultingWebhook creates webhook writing default values into Rabbitmq spec
+func NewDefaultingWebhook(mgr manager.Manager) (*admission.Webhook, error) {
+	failurePolicy := admissionregistrationv1beta1.Fail
+	return &admission.Webhook{
+		Name: "defaulting.rabbitmqs.rabbitmq.improvado.io",
+		Type: types.WebhookTypeMutating,
+		Path: "/mutate-rabbitmqs",
+		Rules: []admissionregistrationv1beta1.RuleWithOperations{
+			{
+				Operations: []admissionregistrationv1beta1.OperationType{
+					admissionregistrationv1beta1.Create,
+					admissionregistrationv1beta1.Update,
+				},
+				Rule: admissionregistrationv1beta1.Rule{
+					APIGroups:   []string{rabbitmqv1.SchemeGroupVersion.Group},
+					APIVersions: []string{rabbitmqv1.SchemeGroupVersion.Version},
+					Resources:   []string{"rabbitmqs"},
+				},
+			},
+		},
+		FailurePolicy: &failurePolicy,
+		Handlers:      []admission.Handler{&rabbitmqDefaulter{}},
+	}, nil
+}
+
+type rabbitmqDefaulter struct {
+	decoder atypes.Decoder
+}
+
+var _ admission.Handler = &rabbitmqDefaulter{}
+var _ inject.Decoder = &rabbitmqDefaulter{}
+
+func (d *rabbitmqDefaulter) InjectDecoder(decoder atypes.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+func (d *rabbitmqDefaulter) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	instance := &rabbitmqv1.Rabbitmq{}
+	if err := d.decoder.Decode(req, instance); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	defaulted := instance.DeepCopy()
+	defaulted.Default()
+
+	return admission.PatchResponse(instance, defaulted)
+}
@@ -0,0 +1,80 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/types"
+)
+
+var log = logf.Log.WithName("webhook_rabbitmq")
+
+// NewValidatingWebhook creates webhook rejecting invalid Rabbitmq specs
+func NewValidatingWebhook(mgr manager.Manager) (*admission.Webhook, error) {
+	failurePolicy := admissionregistrationv1beta1.Fail
+	return &admission.Webhook{
+		Name: "validating.rabbitmqs.rabbitmq.improvado.io",
+		Type: types.WebhookTypeValidating,
+		Path: "/validate-rabbitmqs",
+		Rules: []admissionregistrationv1beta1.RuleWithOperations{
+			{
+				Operations: []admissionregistrationv1beta1.OperationType{
+					admissionregistrationv1beta1.Create,
+					admissionregistrationv1beta1.Update,
+				},
+				Rule: admissionregistrationv1beta1.Rule{
+					APIGroups:   []string{rabbitmqv1.SchemeGroupVersion.Group},
+					APIVersions: []string{rabbitmqv1.SchemeGroupVersion.Version},
+					Resources:   []string{"rabbitmqs"},
+				},
+			},
+		},
+		FailurePolicy: &failurePolicy,
+		Handlers:      []admission.Handler{&rabbitmqValidator{}},
+	}, nil
+}
+
+type rabbitmqValidator struct {
+	decoder atypes.Decoder
+}
+
+var _ admission.Handler = &rabbitmqValidator{}
+var _ inject.Decoder = &rabbitmqValidator{}
+
+func (v *rabbitmqValidator) InjectDecoder(d atypes.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *rabbitmqValidator) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	instance := &rabbitmqv1.Rabbitmq{}
+	if err := v.decoder.Decode(req, instance); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	var err error
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+		oldInstance := &rabbitmqv1.Rabbitmq{}
+		if err := json.Unmarshal(req.AdmissionRequest.OldObject.Raw, oldInstance); err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err)
+		}
+		err = instance.ValidateUpdate(oldInstance)
+	} else {
+		err = instance.ValidateCreate()
+	}
+
+	if err != nil {
+		log.Info("Rabbitmq rejected", "Namespace", req.AdmissionRequest.Namespace, "Name", req.AdmissionRequest.Name, "Reason", err.Error())
+		return admission.ValidationResponse(false, err.Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
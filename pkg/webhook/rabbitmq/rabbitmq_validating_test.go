@@ -0,0 +1,128 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/tekliner/rabbitmq-operator/pkg/apis"
+	rabbitmqv1 "github.com/tekliner/rabbitmq-operator/pkg/apis/rabbitmq/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// legacyRabbitmq was created before validation: image tag and partition handling mode are invalid
+func legacyRabbitmq() *rabbitmqv1.Rabbitmq {
+	return &rabbitmqv1.Rabbitmq{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rabbitmqv1.SchemeGroupVersion.String(), Kind: "Rabbitmq"},
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default", Finalizers: []string{"foreground"}},
+		Spec: rabbitmqv1.RabbitmqSpec{
+			K8SImage:                         rabbitmqv1.RabbitmqImage{Name: "rabbitmq"},
+			RabbitmqClusterPartitionHandling: "unknown",
+			RabbitmqVolumeSize:               resource.MustParse("10Gi"),
+		},
+	}
+}
+
+func rawObject(t *testing.T, obj runtime.Object) runtime.RawExtension {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtime.RawExtension{Raw: raw}
+}
+
+func TestValidatingWebhookUpdate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := &rabbitmqValidator{}
+	if err := validator.InjectDecoder(decoder); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		update  func(*rabbitmqv1.Rabbitmq)
+		allowed bool
+	}{
+		{
+			name: "finalizer removed from deleted legacy object",
+			update: func(r *rabbitmqv1.Rabbitmq) {
+				now := metav1.Now()
+				r.DeletionTimestamp = &now
+				r.Finalizers = nil
+			},
+			allowed: true,
+		},
+		{
+			name: "labels changed on legacy object",
+			update: func(r *rabbitmqv1.Rabbitmq) {
+				r.Labels = map[string]string{"team": "data"}
+			},
+			allowed: true,
+		},
+		{
+			name: "valid field changed on legacy object",
+			update: func(r *rabbitmqv1.Rabbitmq) {
+				r.Spec.RabbitmqReplicas = 3
+			},
+			allowed: true,
+		},
+		{
+			name: "new invalid field",
+			update: func(r *rabbitmqv1.Rabbitmq) {
+				r.Spec.RabbitmqConfigUpdatePolicy = "never"
+			},
+			allowed: false,
+		},
+		{
+			name: "inter-node TLS enabled",
+			update: func(r *rabbitmqv1.Rabbitmq) {
+				r.Spec.RabbitmqSSL = rabbitmqv1.RabbitmqSSL{Enabled: true, ExitingSecret: "legacy-tls", Verify: "verify_peer", InterNodeTLS: true}
+			},
+			allowed: true,
+		},
+		{
+			name: "volume decreased",
+			update: func(r *rabbitmqv1.Rabbitmq) {
+				r.Spec.RabbitmqVolumeSize = resource.MustParse("5Gi")
+			},
+			allowed: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			old := legacyRabbitmq()
+			updated := old.DeepCopy()
+			test.update(updated)
+
+			response := validator.Handle(context.TODO(), atypes.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+				Operation: admissionv1beta1.Update,
+				Name:      updated.Name,
+				Namespace: updated.Namespace,
+				Object:    rawObject(t, updated),
+				OldObject: rawObject(t, old),
+			}})
+			if response.Response.Allowed != test.allowed {
+				t.Errorf("allowed = %v, want %v, result %v", response.Response.Allowed, test.allowed, response.Response.Result)
+			}
+		})
+	}
+}
+
+func TestValidatingWebhookCreateLegacy(t *testing.T) {
+	if err := legacyRabbitmq().ValidateCreate(); err == nil {
+		t.Error("invalid spec accepted on create")
+	}
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var log = logf.Log.WithName("webhook")
+
+// AddToManagerFuncs is a list of functions to create webhooks
+var AddToManagerFuncs []func(manager.Manager) (*admission.Webhook, error)
+
+// server serves admission webhooks over https, certificate and key are read from certDir
+type server struct {
+	port    int32
+	certDir string
+	mux     *http.ServeMux
+}
+
+func (s *server) Start(stop <-chan struct{}) error {
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", s.port),
+		Handler: s.mux,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		log.Info("Starting webhook server", "Port", s.port, "CertDir", s.certDir)
+		errChan <- httpServer.ListenAndServeTLS(filepath.Join(s.certDir, "tls.crt"), filepath.Join(s.certDir, "tls.key"))
+	}()
+
+	select {
+	case <-stop:
+		return httpServer.Shutdown(context.Background())
+	case err := <-errChan:
+		return err
+	}
+}
+
+// AddToManager adds all webhooks to a https server started by the Manager
+func AddToManager(m manager.Manager, port int32, certDir string) error {
+	s := &server{
+		port:    port,
+		certDir: certDir,
+		mux:     http.NewServeMux(),
+	}
+
+	for _, f := range AddToManagerFuncs {
+		wh, err := f(m)
+		if err != nil {
+			return err
+		}
+
+		// inject decoder and client into handlers
+		if err := m.SetFields(wh); err != nil {
+			return err
+		}
+
+		if err := wh.Validate(); err != nil {
+			return err
+		}
+
+		log.Info("Registering webhook", "Name", wh.GetName(), "Path", wh.GetPath())
+		s.mux.Handle(wh.GetPath(), wh)
+	}
+
+	return m.Add(s)
+}